@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -20,6 +21,7 @@ import (
 // ConfBtrfs is the configuration for btrfs data
 type ConfBtrfs struct {
 	ConfBaseWarn `yaml:",inline"`
+	ConfFill     `yaml:",inline"`
 	// Show free space instead of used space
 	ShowFree bool `yaml:"show_free"`
 	// Parse btrfs command output
@@ -30,22 +32,16 @@ type ConfBtrfs struct {
 	Command string `yaml:"btrfs_cmd"`
 }
 
-// Init sets up default alignment
-func (c *ConfBtrfs) Init() {
-	c.ConfBaseWarn.Init()
-	c.PadHeader[1] = 4
+func init() {
+	Register(NewDatasource("btrfs", "BTRFS", func() ConfInterface { return &ConfBtrfs{} }, GetBtrfs))
 }
 
 // GetBtrfs gets btrfs filesystem used and total space by reading files in /sys
-func GetBtrfs(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.BTRFS
-	// Check for warnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetBtrfs(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["btrfs"].(*ConfBtrfs)
+	sr := NewSourceReturn("BTRFS")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	if c.Exec {
 		// Check if we are root
@@ -63,22 +59,22 @@ func GetBtrfs(ch chan<- SourceReturn, conf *Conf) {
 		if c.Sudo {
 			cmd = "sudo " + cmd
 		}
-		sr.Header, sr.Content, sr.Error = getBtrfsStatusExec(cmd, &c)
+		sr.Error = getBtrfsStatusExec(ctx, cmd, &c, sr)
 		return
 	}
-	sr.Header, sr.Content, sr.Error = getBtrfsStatus(&c)
+	sr.Error = getBtrfsStatus(&c, sr)
 }
 
-func getBtrfsStatusExec(cmd string, c *ConfBtrfs) (header string, content string, err error) {
+func getBtrfsStatusExec(ctx context.Context, cmd string, c *ConfBtrfs, sr *SourceReturn) (err error) {
 	// Find all btrfs mounts
-	parts, err := disk.Partitions(false)
+	parts, err := disk.PartitionsWithContext(ctx, false)
 	if err != nil {
+		sr.Status = StatusUnavailable
 		err = &ModuleNotAvailable{"btrfs", err}
 		return
 	}
 	checked := make(map[string]struct{})
 	var empty struct{}
-	var status = 'o'
 	// Group 1: total device size in bytes
 	reSize := regexp.MustCompile(`(?im)^\s+device\s+size:\s+(\d+)`)
 	// Group 1: estimated free space in bytes
@@ -94,7 +90,7 @@ func getBtrfsStatusExec(cmd string, c *ConfBtrfs) (header string, content string
 			checked[p.Device] = empty
 			log.Debugf("btrfs: device %s mounted at %s", p.Device, p.Mountpoint)
 			tmp := append(args, p.Mountpoint)
-			command := exec.Command(tmp[0], tmp[1:]...)
+			command := exec.CommandContext(ctx, tmp[0], tmp[1:]...)
 			log.Debugf("btrfs: exec: '%s'", command.String())
 			var buf bytes.Buffer
 			command.Stdout = &buf
@@ -145,43 +141,25 @@ func getBtrfsStatusExec(cmd string, c *ConfBtrfs) (header string, content string
 			// this is only accurate when data >> metadata
 			totalBytes = totalBytes / dataRatio
 			usedPerc := int((1 - (freeBytes / totalBytes)) * 100)
-			totalStr := utils.FormatBytes(totalBytes)
-			var firstStr string
-			if c.ShowFree {
-				firstStr = utils.FormatBytes(freeBytes) + " free"
-			} else {
-				firstStr = utils.FormatBytes(totalBytes-freeBytes) + " used"
-			}
-			if usedPerc < c.Warn && !*c.WarnOnly {
-				content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(p.Mountpoint, c.padL, c.padR), firstStr, totalStr)
-			} else if usedPerc >= c.Warn && usedPerc < c.Crit {
-				if status != 'e' {
-					status = 'w'
-				}
-				content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(p.Mountpoint, c.padL, c.padR), firstStr, totalStr)
-			} else if usedPerc >= c.Crit {
-				status = 'e'
-				content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(p.Mountpoint, c.padL, c.padR), firstStr, totalStr)
-			}
+			sr.Items = append(sr.Items, Item{
+				Name:    p.Mountpoint,
+				Value:   btrfsUsage(c, totalBytes-freeBytes, totalBytes),
+				Status:  c.Status(usedPerc),
+				Metrics: usageMetrics(&c.ConfBaseWarn, totalBytes-freeBytes, totalBytes),
+			})
 		}
 	}
-	if status == 'o' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Good("OK"))
-	} else if status == 'w' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Warn("Warning"))
-	} else {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Err("Critical"))
-	}
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 	return
 }
 
-func getBtrfsStatus(c *ConfBtrfs) (header string, content string, err error) {
+func getBtrfsStatus(c *ConfBtrfs, sr *SourceReturn) (err error) {
 	matches, err := filepath.Glob("/sys/fs/btrfs/*-*")
 	if err != nil {
+		sr.Status = StatusUnavailable
 		err = &ModuleNotAvailable{"btrfs", err}
 		return
 	}
-	var status = 'o'
 	for _, fs := range matches {
 		// Get FS label
 		var label string
@@ -193,8 +171,6 @@ func getBtrfsStatus(c *ConfBtrfs) (header string, content string, err error) {
 		}
 		var usedBytes float64
 		var totalBytes float64
-		var usedPerc int
-		totalStr := "N/A"
 		// Add data, metadata and system together
 		usedFiles, _ := filepath.Glob(fs + "/allocation/*/bytes_used")
 		for _, file := range usedFiles {
@@ -208,39 +184,29 @@ func getBtrfsStatus(c *ConfBtrfs) (header string, content string, err error) {
 		}
 
 		if usedBytes <= 0 || totalBytes <= 0 {
-			content += fmt.Sprintf("%s: %s\n", utils.Wrap(label, c.padL, c.padR), utils.Err("read error"))
+			sr.AddItem(label, "read error", StatusUnknown)
 			continue
 		}
-		totalStr = utils.FormatBytes(totalBytes)
-		usedPerc = int((usedBytes / totalBytes) * 100)
-		var firstStr string
-		if c.ShowFree {
-			firstStr = utils.FormatBytes(totalBytes-usedBytes) + " free"
-		} else {
-			firstStr = utils.FormatBytes(usedBytes) + " used"
-		}
-		if usedPerc < c.Warn && !*c.WarnOnly {
-			content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(label, c.padL, c.padR), firstStr, totalStr)
-		} else if usedPerc >= c.Warn && usedPerc < c.Crit {
-			if status != 'e' {
-				status = 'w'
-			}
-			content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(label, c.padL, c.padR), firstStr, totalStr)
-		} else if usedPerc >= c.Crit {
-			status = 'e'
-			content += fmt.Sprintf("%s: %s out of %s\n", utils.Wrap(label, c.padL, c.padR), firstStr, totalStr)
-		}
-	}
-	if status == 'o' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Good("OK"))
-	} else if status == 'w' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Warn("Warning"))
-	} else {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("BTRFS", c.padL, c.padR), utils.Err("Critical"))
+		usedPerc := int((usedBytes / totalBytes) * 100)
+		sr.Items = append(sr.Items, Item{
+			Name:    label,
+			Value:   btrfsUsage(c, usedBytes, totalBytes),
+			Status:  c.Status(usedPerc),
+			Metrics: usageMetrics(&c.ConfBaseWarn, usedBytes, totalBytes),
+		})
 	}
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 	return
 }
 
+// btrfsUsage formats used or free space depending on ShowFree
+func btrfsUsage(c *ConfBtrfs, usedBytes float64, totalBytes float64) string {
+	if c.ShowFree {
+		return fmt.Sprintf("%s free out of %s", utils.FormatBytes(totalBytes-usedBytes), utils.FormatBytes(totalBytes))
+	}
+	return fmt.Sprintf("%s used out of %s", utils.FormatBytes(usedBytes), utils.FormatBytes(totalBytes))
+}
+
 func readFloatFile(file string) float64 {
 	readBytes, err := os.ReadFile(file)
 	if err != nil {
@@ -0,0 +1,60 @@
+package datasources
+
+import "fmt"
+
+// Column alignments
+const (
+	AlignLeft   = "left"
+	AlignRight  = "right"
+	AlignCenter = "center"
+)
+
+// Column is a module in a col_def row, either its name or a mapping with the name, width and alignment
+type Column struct {
+	// Name of the module
+	Name string `yaml:"name"`
+	// Minimum width of the column, the widest line is used if 0
+	Width int `yaml:"width,omitempty"`
+	// Alignment of the lines within the column, left by default
+	Align string `yaml:"align,omitempty"`
+}
+
+// column is used to decode the mapping form of Column without recursing into UnmarshalYAML
+type column Column
+
+// UnmarshalYAML decodes a module name or a mapping with name, width and align
+func (c *Column) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&c.Name); err == nil {
+		return nil
+	}
+	return unmarshal((*column)(c))
+}
+
+// MarshalYAML encodes the column as its name unless it has a width or alignment
+func (c Column) MarshalYAML() (interface{}, error) {
+	if c.Width == 0 && c.Align == "" {
+		return c.Name, nil
+	}
+	return column(c), nil
+}
+
+// Validate checks the width and alignment, the name is checked against the registry by Conf
+func (c *Column) Validate() error {
+	if c.Width < 0 {
+		return fmt.Errorf("%s: width cannot be negative", c.Name)
+	}
+	switch c.Align {
+	case "", AlignLeft, AlignRight, AlignCenter:
+		return nil
+	}
+	return fmt.Errorf("%s: unknown alignment %s", c.Name, c.Align)
+}
+
+// ColumnNames returns the module names of a col_def row
+func ColumnNames(row []Column) []string {
+	names := make([]string, len(row))
+	for i, c := range row {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -1,16 +1,15 @@
 package datasources
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
-
-	"github.com/cosandr/go-motd/utils"
 )
 
 type UnavailableError interface {
@@ -27,46 +26,61 @@ func (m *ModuleNotAvailable) Error() string {
 	return "module " + m.Name + " is not available: " + m.ParentError.Error()
 }
 
+func (m *ModuleNotAvailable) Unwrap() error {
+	return m.ParentError
+}
+
 func (ModuleNotAvailable) UnavailableError() {}
 
 // SourceReturn is the data returned by a datasource through a channel
 type SourceReturn struct {
-	// Datasource output header string
-	Header string
-	// Datasource output content string
-	Content string
+	// Module title shown in the header, if empty only the items are shown
+	Title string
+	// Overall status of the module
+	Status Status
+	// Message replaces the status name in the header if set
+	Message string
+	// Items gathered by the module
+	Items []Item
+	// Numeric measurements which apply to the whole module
+	Metrics []Metric
+	// Recent samples of the main module metric from oldest to newest
+	Trend []float64
 	// Error
 	Error error
-	// Time taken, non-zero only in debug mode
+	// Time taken
 	Time time.Duration
 	// Internal
 	start time.Time
 }
 
-func (sr *SourceReturn) Return(c *ConfBase) SourceReturn {
-	if !sr.start.IsZero() {
-		sr.Time = time.Since(sr.start)
-	}
-	sr.MaybePad(c)
+// Return sets the time taken and returns the result
+func (sr *SourceReturn) Return() SourceReturn {
+	sr.Time = time.Since(sr.start)
 	return *sr
 }
 
-func (sr *SourceReturn) MaybePad(c *ConfBase) {
-	sr.Header, sr.Content = c.MaybePad(sr.Header, sr.Content)
+// HasData reports whether the module returned its data, it did not if it is unavailable, returned an error
+// or is unknown without items, such as after a timeout
+func (sr *SourceReturn) HasData() bool {
+	return sr.Status != StatusUnavailable && sr.Error == nil && (sr.Status != StatusUnknown || len(sr.Items) > 0)
 }
 
-func NewSourceReturn(debug bool) *SourceReturn {
-	sr := SourceReturn{}
-	if debug {
-		sr.start = time.Now()
-	}
-	return &sr
+// AddItem appends an item to the result
+func (sr *SourceReturn) AddItem(name string, value string, status Status) {
+	sr.Items = append(sr.Items, Item{Name: name, Value: value, Status: status})
+}
 
+// NewSourceReturn returns a result for a module with the given title and starts timing it
+func NewSourceReturn(title string) *SourceReturn {
+	return &SourceReturn{Title: title, Status: StatusOK, start: time.Now()}
 }
 
 // ConfInterface defines the interface for config structs
 type ConfInterface interface {
 	Init()
+	// Base returns the common config, implemented by embedding ConfBase
+	Base() *ConfBase
 }
 
 // ConfBase is the common type for all modules
@@ -75,35 +89,33 @@ type ConfInterface interface {
 type ConfBase struct {
 	// Override global setting
 	WarnOnly *bool `yaml:"warnings_only,omitempty"`
-	// 2-element array defining padding for header (title)
-	PadHeader []int `yaml:"pad_header,flow"`
-	// 2-element array defining padding for content (details)
-	PadContent []int `yaml:"pad_content,flow"`
-	padL       string
-	padR       string
-}
-
-// Init sets `PadHeader` and `PadContent` to [0, 0]
-func (c *ConfBase) Init() {
-	c.PadHeader = []int{0, 0}
-	c.PadContent = []int{1, 0}
-	c.padL = "^L^"
-	c.padR = "^R^"
-}
-
-// MaybePad pads header and content (if they aren't empty strings)
-func (c *ConfBase) MaybePad(header string, content string) (string, string) {
-	var rh string
-	var rc string
-	if len(header) > 0 {
-		p := utils.Pad{Delims: map[string]int{c.padL: c.PadHeader[0], c.padR: c.PadHeader[1]}, Content: header}
-		rh = p.Do()
-	}
-	if len(content) > 0 {
-		p := utils.Pad{Delims: map[string]int{c.padL: c.PadContent[0], c.padR: c.PadContent[1]}, Content: content}
-		rc = p.Do()
-	}
-	return rh, rc
+	// 2-element array defining padding for header (title), spaces before and after the label.
+	// If not set, the labels of all headers in the same column are aligned.
+	PadHeader []int `yaml:"pad_header,flow,omitempty"`
+	// 2-element array defining padding for content (details), defaults to [1, 0]
+	PadContent []int `yaml:"pad_content,flow,omitempty"`
+	// Override global timeout
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Show a usage bar before items with a percentage, colored by their thresholds
+	Bar bool `yaml:"bar,omitempty"`
+	// Width of the usage bar, 20 if not set
+	BarWidth int `yaml:"bar_width,omitempty"`
+	// Draw the usage bar with ASCII characters instead of Unicode blocks
+	BarASCII bool `yaml:"bar_ascii,omitempty"`
+	// Show a sparkline of recent samples after values, only in daemon mode with trend_samples set
+	Sparkline bool `yaml:"sparkline,omitempty"`
+	// Width of the sparkline, 10 if not set
+	SparklineWidth int `yaml:"sparkline_width,omitempty"`
+	// Override global hooks
+	ConfHooks `yaml:",inline"`
+}
+
+// Init leaves the padding unset, it is chosen by the layout
+func (c *ConfBase) Init() {}
+
+// Base returns itself, it allows accessing the common options of any module config
+func (c *ConfBase) Base() *ConfBase {
+	return c
 }
 
 // ConfBaseWarn extends ConfBase with warning and critical values
@@ -120,6 +132,33 @@ func (c *ConfBaseWarn) Init() {
 	c.Crit = 90
 }
 
+// Status returns the status of v according to the warning and critical thresholds
+func (c *ConfBaseWarn) Status(v int) Status {
+	if v >= c.Crit {
+		return StatusCritical
+	} else if v >= c.Warn {
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// Metric returns a metric with the warning and critical thresholds set
+func (c *ConfBaseWarn) Metric(name string, value float64) Metric {
+	return Metric{Name: name, Value: value, Warn: float64(c.Warn), Crit: float64(c.Crit)}
+}
+
+// usageMetrics returns used and total bytes as well as the usage percentage compared to the thresholds
+func usageMetrics(c *ConfBaseWarn, usedBytes float64, totalBytes float64) []Metric {
+	usedPerc := c.Metric("used_percent", usedBytes/totalBytes*100)
+	usedPerc.Unit = "%"
+	usedPerc.Max = 100
+	return []Metric{
+		{Name: "used_bytes", Value: usedBytes, Unit: "B", Max: totalBytes},
+		{Name: "total_bytes", Value: totalBytes, Unit: "B"},
+		usedPerc,
+	}
+}
+
 // ConfGlobal is the config struct for global settings
 type ConfGlobal struct {
 	// Hide fields which are deemed to be OK
@@ -127,25 +166,74 @@ type ConfGlobal struct {
 	// Order in which to display data sources
 	ShowOrder []string `yaml:"show_order,flow,omitempty"`
 	// Define how data sources are displayed
-	ColDef [][]string `yaml:"col_def,flow,omitempty"`
+	ColDef [][]Column `yaml:"col_def,flow,omitempty"`
 	// Padding between columns when using col_def
 	ColPad int `yaml:"col_pad"`
+	// Maximum width of the text output, the terminal width is used for stdout if 0
+	MaxWidth int `yaml:"max_width,omitempty"`
+	// Maximum time a module may run for
+	Timeout time.Duration `yaml:"timeout"`
+	// Files written in daemon mode
+	Outputs []Output `yaml:"outputs,omitempty"`
+	// Go text/template used instead of the default text layout, inline or a path to a file
+	Template string `yaml:"template,omitempty"`
+	// Remember results between runs and show what changed since the previous one
+	History bool `yaml:"history,omitempty"`
+	// File the previous results are kept in, a per-user or system path is used if empty
+	StateFile string `yaml:"state_file,omitempty"`
+	// Number of samples kept per item in daemon mode for sparklines, disabled if 0
+	TrendSamples int `yaml:"trend_samples,omitempty"`
+	// File the samples are saved to so they are kept across restarts, optional
+	TrendFile string `yaml:"trend_file,omitempty"`
+	// URLs status changes are posted to in daemon mode
+	Webhooks []Webhook `yaml:"webhooks,omitempty"`
+	// Commands run in daemon mode when a status changes
+	ConfHooks `yaml:",inline"`
+	// Maximum time a hook may run for, 1m if not set
+	HookTimeout time.Duration `yaml:"hook_timeout,omitempty"`
 	// Internal variables
 	debug bool
 }
 
+// Debug returns true if debug mode is enabled
+func (c *ConfGlobal) Debug() bool {
+	return c.debug
+}
+
+// ModuleTimeout returns the timeout for module name, the global setting is used unless overridden
+func (c *Conf) ModuleTimeout(name string) time.Duration {
+	if mc, ok := c.Modules[name]; ok && mc.Base().Timeout > 0 {
+		return mc.Base().Timeout
+	}
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultTimeout
+}
+
+// defaultTimeout is used if the global timeout is not set
+const defaultTimeout = 5 * time.Second
+
+// globalKey is the config section for global settings, it cannot be used as a datasource name
+const globalKey = "global"
+
+// ChangesKey is the name of the result listing changes since the previous run, it cannot be used as a datasource name
+const ChangesKey = "changes"
+
 // Conf is the combined config struct, defines YAML file
+//
+// The global section is decoded into ConfGlobal, the theme section into Theme and all other
+// sections are decoded into the config of the registered datasource with the same name.
 type Conf struct {
-	ConfGlobal `yaml:"global"`
-	BTRFS      ConfBtrfs    `yaml:"btrfs"`
-	CPU        ConfTempCPU  `yaml:"cpu"`
-	Disk       ConfTempDisk `yaml:"disk"`
-	Docker     ConfDocker   `yaml:"docker"`
-	Podman     ConfPodman   `yaml:"podman"`
-	SysInfo    ConfSysInfo  `yaml:"sysinfo"`
-	Systemd    ConfSystemd  `yaml:"systemd"`
-	Updates    ConfUpdates  `yaml:"updates"`
-	ZFS        ConfZFS      `yaml:"zfs"`
+	ConfGlobal
+	// Colors
+	Theme ConfTheme
+	// Module configs, keyed by datasource name
+	Modules map[string]ConfInterface
+	// Sections without a registered datasource, reset after every decoded file
+	unknown []string
+	// File which last set a section or key, keyed by section or section.key
+	origins map[string]string
 }
 
 // Init a config with sane default values
@@ -153,76 +241,168 @@ func (c *Conf) Init() {
 	// Set global defaults
 	c.WarnOnly = true
 	c.ColPad = 4
+	c.Timeout = defaultTimeout
+	c.Theme.Init()
 	// Init data source configs
-	c.BTRFS.Init()
-	c.CPU.Init()
-	c.Disk.Init()
-	c.Docker.Init()
-	c.Podman.Init()
-	c.SysInfo.Init()
-	c.Systemd.Init()
-	c.Updates.Init()
-	c.ZFS.Init()
-}
-
-func NewConfFromFile(path string, debug bool) (c Conf, err error) {
-	c.Init()
-	c.debug = debug
-	yamlFile, errF := os.ReadFile(path)
-	if errF != nil {
-		err = fmt.Errorf("config file error: %v ", err)
-		return
+	c.Modules = make(map[string]ConfInterface)
+	for _, k := range Names() {
+		ds, _ := Lookup(k)
+		mc := ds.NewConf()
+		mc.Init()
+		c.Modules[k] = mc
+	}
+}
+
+// ModuleWarnOnly returns the warnings_only setting for module name, the global setting is used unless overridden
+func (c *Conf) ModuleWarnOnly(name string) bool {
+	if mc, ok := c.Modules[name]; ok && mc.Base().WarnOnly != nil {
+		return *mc.Base().WarnOnly
+	}
+	return c.WarnOnly
+}
+
+// ModuleBase returns the common config of module name, an empty config is returned for results without a module
+func (c *Conf) ModuleBase(name string) *ConfBase {
+	if mc, ok := c.Modules[name]; ok {
+		return mc.Base()
+	}
+	return &ConfBase{}
+}
+
+// yamlSection keeps the decode function of a config section so it can be decoded later
+type yamlSection struct {
+	unmarshal func(interface{}) error
+}
+
+func (y *yamlSection) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	y.unmarshal = unmarshal
+	return nil
+}
+
+// UnmarshalYAML decodes the global and theme sections and the sections of all registered datasources
+func (c *Conf) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if c.Modules == nil {
+		c.Init()
+	}
+	var sections map[string]yamlSection
+	if err := unmarshal(&sections); err != nil {
+		return err
+	}
+	// Decode all sections so every type error is reported
+	var issues []string
+	for k, v := range sections {
+		var err error
+		if k == globalKey {
+			err = v.unmarshal(&c.ConfGlobal)
+		} else if k == themeKey {
+			err = v.unmarshal(&c.Theme)
+		} else if mc, ok := c.Modules[k]; ok {
+			err = v.unmarshal(mc)
+		} else {
+			c.unknown = append(c.unknown, k)
+			continue
+		}
+		if te, ok := err.(*yaml.TypeError); ok {
+			issues = append(issues, te.Errors...)
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+	}
+	sort.Strings(c.unknown)
+	if len(issues) > 0 {
+		return &yaml.TypeError{Errors: issues}
+	}
+	return nil
+}
+
+// MarshalYAML encodes the global and theme sections first, followed by the datasources sorted by name
+func (c *Conf) MarshalYAML() (interface{}, error) {
+	out := yaml.MapSlice{{Key: globalKey, Value: &c.ConfGlobal}, {Key: themeKey, Value: &c.Theme}}
+	names := make([]string, 0, len(c.Modules))
+	for k := range c.Modules {
+		names = append(names, k)
 	}
-	err = yaml.Unmarshal(yamlFile, &c)
-	if err != nil {
-		err = fmt.Errorf("cannot parse %s: %v", path, err)
-		return
+	sort.Strings(names)
+	for _, k := range names {
+		out = append(out, yaml.MapItem{Key: k, Value: c.Modules[k]})
 	}
-	return
+	return out, nil
+}
+
+// runningSource is a datasource which has been started by RunSources
+type runningSource struct {
+	ch      chan SourceReturn
+	ctx     context.Context
+	title   string
+	timeout time.Duration
 }
 
 // RunSources runs data sources in runList, the names are validated and returned as the first value
-func RunSources(runList []string, c *Conf) ([]string, map[string]SourceReturn) {
-	channels := make(map[string]chan SourceReturn)
+//
+// Each data source is cancelled after its timeout, a data source which did not return in time
+// gets a result with an unknown status and a "timed out" message.
+func RunSources(ctx context.Context, runList []string, c *Conf) ([]string, map[string]SourceReturn) {
+	running := make(map[string]runningSource)
 	out := make(map[string]SourceReturn)
 	var validRuns []string
 	// Start goroutines
-Loop:
 	for _, k := range runList {
-		ch := make(chan SourceReturn, 1)
-		switch k {
-		case "btrfs":
-			go GetBtrfs(ch, c)
-		case "cpu":
-			go GetCPUTemp(ch, c)
-		case "disk":
-			go GetDiskTemps(ch, c)
-		case "docker":
-			go GetDocker(ch, c)
-		case "podman":
-			go GetPodman(ch, c)
-		case "sysinfo":
-			go GetSysInfo(ch, c)
-		case "systemd":
-			go GetSystemd(ch, c)
-		case "updates":
-			go GetUpdates(ch, c)
-		case "zfs":
-			go GetZFS(ch, c)
-		default:
+		ds, ok := Lookup(k)
+		if !ok {
 			log.Warnf("no data source named %s", k)
-			continue Loop
+			continue
+		}
+		if _, ok := c.Modules[k]; !ok {
+			log.Warnf("no config for data source %s", k)
+			continue
+		}
+		rs := runningSource{ch: make(chan SourceReturn, 1), title: ds.Title(), timeout: c.ModuleTimeout(k)}
+		if rs.title == "" {
+			rs.title = k
 		}
-		channels[k] = ch
+		var cancel context.CancelFunc
+		rs.ctx, cancel = context.WithTimeout(ctx, rs.timeout)
+		defer cancel()
+		ch := make(chan SourceReturn, 1)
+		go ds.Run(rs.ctx, ch, c)
+		// Forward results which arrive before the deadline, they are kept even if it passes before they are read
+		go func(rs runningSource) {
+			sr := <-ch
+			if rs.ctx.Err() == nil {
+				rs.ch <- sr
+			}
+		}(rs)
+		running[k] = rs
 		validRuns = append(validRuns, k)
 	}
 	// Wait for results
 	log.Debug("Wait for goroutines")
-	for k := range channels {
-		out[k] = <-channels[k]
+	for k, rs := range running {
+		select {
+		case sr := <-rs.ch:
+			out[k] = sr
+			continue
+		case <-rs.ctx.Done():
+			// Prefer a result which was forwarded just before the deadline
+			select {
+			case sr := <-rs.ch:
+				out[k] = sr
+				continue
+			default:
+			}
+		}
+		log.Debugf("%s did not finish: %v", k, rs.ctx.Err())
+		sr := SourceReturn{Title: rs.title, Status: StatusUnknown, Time: rs.timeout}
+		if errors.Is(rs.ctx.Err(), context.DeadlineExceeded) {
+			sr.Message = "timed out"
+			sr.Error = fmt.Errorf("%s timed out after %s: %w", k, rs.timeout, rs.ctx.Err())
+		} else {
+			sr.Message = "cancelled"
+			sr.Error = rs.ctx.Err()
+		}
+		out[k] = sr
 	}
 	return validRuns, out
-
 }
 
 type timeEntry struct {
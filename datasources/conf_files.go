@@ -0,0 +1,298 @@
+package datasources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// confDir is the drop-in directory next to the main config file
+const confDir = "conf.d"
+
+// ConfigFiles returns path followed by the *.yaml files in the conf.d directory next to it, in lexical order
+func ConfigFiles(path string) []string {
+	// Glob sorts matches and only fails on bad patterns
+	fragments, _ := filepath.Glob(filepath.Join(filepath.Dir(path), confDir, "*.yaml"))
+	return append([]string{path}, fragments...)
+}
+
+// NewConfFromFile reads the config at path merged with its conf.d fragments and validates it. Invalid values are
+// returned as an error with the decoded config, the default config is returned if a file cannot be read or parsed.
+//
+// Unknown sections are logged, unknown keys are ignored; use CheckConfig to report them.
+func NewConfFromFile(path string, debug bool) (Conf, error) {
+	return NewConfWithOverlay(path, "", debug)
+}
+
+// NewConfWithOverlay is the same as NewConfFromFile, the per-user overlay file is merged last if it is not empty.
+// Thresholds cannot be changed by the overlay, they are reset to the system values. The overlay is skipped with
+// a warning if it cannot be read or parsed.
+func NewConfWithOverlay(path string, overlay string, debug bool) (c Conf, err error) {
+	c.Init()
+	usable, err := c.load(ConfigFiles(path), overlay, false)
+	if err != nil {
+		err = fmt.Errorf("invalid config:\n%v", err)
+	}
+	if !usable {
+		c = Conf{}
+		c.Init()
+	}
+	c.debug = debug
+	return
+}
+
+// CheckConfig strictly decodes and validates the config at path merged with its conf.d fragments and
+// the per-user overlay if it is not empty, all problems found are returned as ConfigErrors
+func CheckConfig(path string, overlay string) (c Conf, err error) {
+	c.Init()
+	_, err = c.load(ConfigFiles(path), overlay, true)
+	return
+}
+
+// Origin returns the file which set key in section, the file which last set the section if key
+// is empty or an empty string if it has its default value
+func (c *Conf) Origin(section string, key string) string {
+	if key != "" {
+		if f, ok := c.origins[section+"."+key]; ok {
+			return f
+		}
+	}
+	return c.origins[section]
+}
+
+// load decodes files in order followed by overlay, keys set in later files override earlier ones and lists
+// are replaced. The merged result is validated and all problems are returned as ConfigErrors.
+//
+// usable is false if one of files cannot be read or parsed. Unless strict is set, an overlay which cannot be
+// read or parsed is skipped with a warning.
+func (c *Conf) load(files []string, overlay string, strict bool) (usable bool, err error) {
+	var errs ConfigErrors
+	usable = true
+	contents := make(map[string][]byte)
+	fileIndex := make(map[string]int)
+	if overlay != "" {
+		files = append(files, overlay)
+	}
+	for i, f := range files {
+		fileIndex[f] = i
+		data, err := os.ReadFile(f)
+		if err == nil && f == overlay && !strict {
+			// Syntax errors are found before anything is decoded
+			err = yaml.Unmarshal(data, &yaml.MapSlice{})
+		}
+		if err != nil && f == overlay && !strict {
+			log.Warnf("skipping per-user config: %v", err)
+			continue
+		} else if err != nil {
+			errs = append(errs, ConfigError{File: f, Msg: err.Error()})
+			usable = false
+			continue
+		}
+		contents[f] = data
+		if f != overlay {
+			fileErrs, parsed := c.decode(f, data, strict)
+			errs = append(errs, fileErrs...)
+			usable = usable && parsed
+			continue
+		}
+		saved := c.saveThresholds()
+		fileErrs, _ := c.decode(f, data, strict)
+		errs = append(errs, fileErrs...)
+		errs = append(errs, c.restoreThresholds(f, data, saved, strict)...)
+	}
+	for _, ce := range c.validate() {
+		ce.File = c.Origin(ce.Section, ce.Key)
+		ce.Line = keyLine(contents[ce.File], ce.Section, ce.Key)
+		errs = append(errs, ce)
+	}
+	if len(errs) == 0 {
+		return usable, nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if fileIndex[errs[i].File] != fileIndex[errs[j].File] {
+			return fileIndex[errs[i].File] < fileIndex[errs[j].File]
+		}
+		return errs[i].Line < errs[j].Line
+	})
+	return usable, errs
+}
+
+// decode decodes data read from file on top of c, unknown keys and sections are reported as errors if strict is set.
+// parsed is false if data is not valid YAML, values of the wrong type are only reported.
+func (c *Conf) decode(file string, data []byte, strict bool) (errs ConfigErrors, parsed bool) {
+	var err error
+	if strict {
+		err = yaml.UnmarshalStrict(data, c)
+	} else {
+		err = yaml.Unmarshal(data, c)
+	}
+	if te, ok := err.(*yaml.TypeError); ok {
+		for _, s := range te.Errors {
+			ce := parseTypeError(data, s)
+			ce.File = file
+			errs = append(errs, ce)
+		}
+	} else if err != nil {
+		return ConfigErrors{{File: file, Msg: err.Error()}}, false
+	}
+	for _, k := range c.unknown {
+		if strict {
+			errs = append(errs, ConfigError{File: file, Line: keyLine(data, k, ""), Section: k, Msg: "no data source with this name"})
+		} else {
+			log.Warnf("config %s: no data source named %s", file, k)
+		}
+	}
+	c.unknown = nil
+	c.recordOrigins(file, data)
+	return errs, true
+}
+
+// thresholder is implemented by module configs embedding ConfBaseWarn
+type thresholder interface {
+	thresholds() *ConfBaseWarn
+}
+
+func (c *ConfBaseWarn) thresholds() *ConfBaseWarn {
+	return c
+}
+
+// moduleThresholds returns the thresholds of a module config keyed by their config key
+func moduleThresholds(mc ConfInterface) map[string]*int {
+	t := make(map[string]*int)
+	if w, ok := mc.(thresholder); ok {
+		b := w.thresholds()
+		t["warn"], t["crit"] = &b.Warn, &b.Crit
+	}
+	if f, ok := mc.(filler); ok {
+		fc := f.fill()
+		t["full_at"], t["warn_days"], t["crit_days"] = &fc.FullAt, &fc.WarnDays, &fc.CritDays
+	}
+	return t
+}
+
+// savedThreshold is the value of a threshold and the file it was set in
+type savedThreshold struct {
+	value  int
+	origin string
+}
+
+// saveThresholds returns the thresholds of all modules keyed by section and key
+func (c *Conf) saveThresholds() map[string]savedThreshold {
+	saved := make(map[string]savedThreshold)
+	for k, mc := range c.Modules {
+		for key, v := range moduleThresholds(mc) {
+			saved[k+"."+key] = savedThreshold{*v, c.origins[k+"."+key]}
+		}
+	}
+	return saved
+}
+
+// restoreThresholds resets the thresholds set by file to their saved values, they are reported as errors if strict is set
+func (c *Conf) restoreThresholds(file string, data []byte, saved map[string]savedThreshold, strict bool) (errs ConfigErrors) {
+	names := make([]string, 0, len(c.Modules))
+	for k := range c.Modules {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		thresholds := moduleThresholds(c.Modules[k])
+		keys := make([]string, 0, len(thresholds))
+		for key := range thresholds {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s, ok := saved[k+"."+key]
+			if !ok || c.origins[k+"."+key] != file {
+				continue
+			}
+			*thresholds[key] = s.value
+			if s.origin != "" {
+				c.origins[k+"."+key] = s.origin
+			} else {
+				delete(c.origins, k+"."+key)
+			}
+			ce := ConfigError{File: file, Line: keyLine(data, k, key), Section: k, Key: key, Msg: "can only be set in the system config"}
+			if strict {
+				errs = append(errs, ce)
+			} else {
+				log.Warn(ce.Error())
+			}
+		}
+	}
+	return
+}
+
+// recordOrigins marks the sections and keys present in data as set by file
+func (c *Conf) recordOrigins(file string, data []byte) {
+	var doc yaml.MapSlice
+	// Decode errors are reported by decode
+	_ = yaml.Unmarshal(data, &doc)
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	for _, section := range doc {
+		name := fmt.Sprint(section.Key)
+		c.origins[name] = file
+		keys, ok := section.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		for _, k := range keys {
+			c.origins[name+"."+fmt.Sprint(k.Key)] = file
+		}
+	}
+}
+
+// MarshalWithOrigins encodes c as YAML with a comment naming the file which set each key,
+// keys without a comment have their default value
+func (c *Conf) MarshalWithOrigins() ([]byte, error) {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(out), "\n")
+	var section string
+	// Comments are added to the last line of a value, long flow sequences are wrapped over several lines
+	last, origin := -1, ""
+	annotate := func() {
+		if last >= 0 && origin != "" {
+			lines[last] += " # " + origin
+		}
+		last, origin = -1, ""
+	}
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		depth := len(line) - len(trimmed)
+		key, _, ok := strings.Cut(trimmed, ":")
+		// Only sections and their direct keys are annotated
+		if !ok || depth > 2 || strings.HasPrefix(trimmed, "- ") {
+			if last >= 0 && depth > 2 {
+				last = i
+			}
+			continue
+		}
+		annotate()
+		last = i
+		if depth == 0 {
+			section = key
+			origin = c.origins[section]
+		} else {
+			origin = c.origins[section+"."+key]
+		}
+		// Block values continue on the next lines, annotate the key instead
+		if strings.HasSuffix(trimmed, ":") {
+			annotate()
+		}
+	}
+	annotate()
+	return []byte(strings.Join(lines, "\n")), nil
+}
@@ -1,7 +1,6 @@
 package datasources
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 
@@ -19,46 +18,41 @@ type containerList struct {
 	Containers []containerStatus
 }
 
-func (cl *containerList) toHeaderContent(ignoreList []string, warnOnly bool, padL string, padR string) (header string, content string, err error) {
+// addTo adds the containers to sr and sets its status
+func (cl *containerList) addTo(sr *SourceReturn, ignoreList []string) {
 	// Make set of ignored containers
 	var ignoreSet utils.StringSet
 	ignoreSet = ignoreSet.FromList(ignoreList)
 	// Process output
-	var goodCont = make(map[string]string)
-	var failedCont = make(map[string]string)
-	var sortedNames []string
+	var numGood int
+	var numFailed int
+	var containers []Item
 	for _, c := range cl.Containers {
 		if ignoreSet.Contains(c.Name) {
 			continue
 		}
 		status := strings.ToLower(c.Status)
 		if status == "up" || status == "created" || status == "running" {
-			goodCont[c.Name] = status
+			containers = append(containers, Item{Name: c.Name, Value: status, Status: StatusOK})
+			numGood++
 		} else {
-			failedCont[c.Name] = status
+			containers = append(containers, Item{Name: c.Name, Value: status, Status: StatusCritical})
+			numFailed++
 		}
-		sortedNames = append(sortedNames, c.Name)
 	}
-	sort.Strings(sortedNames)
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Name < containers[j].Name
+	})
+	sr.Items = append(sr.Items, containers...)
+	// Containers which are up or created
+	sr.Metrics = []Metric{{Name: "running", Value: float64(numGood)}}
 
-	// Decide what header should be
-	if len(goodCont) == 0 && len(sortedNames) > 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap(cl.Runtime, padL, padR), utils.Err("critical"))
-	} else if len(failedCont) == 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap(cl.Runtime, padL, padR), utils.Good("OK"))
-		if warnOnly {
-			return
-		}
-	} else if len(failedCont) < len(sortedNames) {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap(cl.Runtime, padL, padR), utils.Warn("warning"))
-	}
-	// Only print all containers if requested
-	for _, c := range sortedNames {
-		if val, ok := goodCont[c]; ok && !warnOnly {
-			content += fmt.Sprintf("%s: %s\n", utils.Wrap(c, padL, padR), utils.Good(val))
-		} else if val, ok := failedCont[c]; ok {
-			content += fmt.Sprintf("%s: %s\n", utils.Wrap(c, padL, padR), utils.Err(val))
-		}
+	// Decide what the status should be
+	if numGood == 0 && len(containers) > 0 {
+		sr.Status = StatusCritical
+	} else if numFailed == 0 {
+		sr.Status = StatusOK
+	} else {
+		sr.Status = StatusWarning
 	}
-	return
 }
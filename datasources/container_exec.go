@@ -2,12 +2,13 @@ package datasources
 
 import (
 	"bytes"
+	"context"
 	"os/exec"
 	"strings"
 )
 
 // getContainersExec returns container status using os/exec, ~5x slower than API
-func getContainersExec(podman bool, sudo bool) (cl containerList, err error) {
+func getContainersExec(ctx context.Context, podman bool, sudo bool) (cl containerList, err error) {
 	var stdout bytes.Buffer
 	cl.Root = sudo
 	if podman {
@@ -17,9 +18,9 @@ func getContainersExec(podman bool, sudo bool) (cl containerList, err error) {
 	}
 	var cmd *exec.Cmd
 	if sudo {
-		cmd = exec.Command("sudo", strings.ToLower(cl.Runtime), "ps", "--format", `"{{.Names}} {{.Status}}"`, "-a")
+		cmd = exec.CommandContext(ctx, "sudo", strings.ToLower(cl.Runtime), "ps", "--format", `"{{.Names}} {{.Status}}"`, "-a")
 	} else {
-		cmd = exec.Command(strings.ToLower(cl.Runtime), "ps", "--format", `"{{.Names}} {{.Status}}"`, "-a")
+		cmd = exec.CommandContext(ctx, strings.ToLower(cl.Runtime), "ps", "--format", `"{{.Names}} {{.Status}}"`, "-a")
 	}
 	cmd.Stdout = &stdout
 	err = cmd.Run()
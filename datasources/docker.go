@@ -2,13 +2,10 @@ package datasources
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
-
-	"github.com/cosandr/go-motd/utils"
 )
 
 const (
@@ -24,45 +21,39 @@ type ConfDocker struct {
 	Ignore []string `yaml:"ignore,omitempty"`
 }
 
-// Init sets up default alignment
-func (c *ConfDocker) Init() {
-	c.ConfBase.Init()
-	c.PadHeader[1] = 3
+func init() {
+	Register(NewDatasource("docker", "Docker", func() ConfInterface { return &ConfDocker{} }, GetDocker))
 }
 
 // GetDocker docker container status using the API
-func GetDocker(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.Docker
-	// Check for warnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetDocker(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["docker"].(*ConfDocker)
+	sr := NewSourceReturn("Docker")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	var err error
 	var cl containerList
 	if c.Exec {
-		cl, err = getContainersExec(false, false)
+		cl, err = getContainersExec(ctx, false, false)
 	} else {
-		cl, err = getDockerContainers()
+		cl, err = getDockerContainers(ctx)
 	}
 	if err != nil {
-		err = &ModuleNotAvailable{"docker", err}
-		sr.Header = fmt.Sprintf("%s: %s\n", utils.Wrap("Docker", c.padL, c.padR), utils.Warn("unavailable"))
+		sr.Status = StatusUnavailable
+		sr.Error = &ModuleNotAvailable{"docker", err}
 	} else {
-		sr.Header, sr.Content, sr.Error = cl.toHeaderContent(c.Ignore, *c.WarnOnly, c.padL, c.padR)
+		cl.addTo(sr, c.Ignore)
 	}
 }
 
-func getDockerContainers() (cl containerList, err error) {
+func getDockerContainers(ctx context.Context) (cl containerList, err error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion(dockerMinAPI))
 	if err != nil {
 		return
 	}
 
-	allContainers, err := cli.ContainerList(context.Background(), container.ListOptions{All: true})
+	allContainers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
 	if err != nil {
 		return
 	}
@@ -0,0 +1,169 @@
+package datasources
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// minFillSamples is the number of samples needed to predict when a filesystem is full
+const minFillSamples = 3
+
+// minStatusSamples and minStatusSpan are the number of samples and the time they must cover before
+// a prediction can change the status, fewer samples are too noisy to warn about
+const (
+	minStatusSamples = 10
+	minStatusSpan    = time.Hour
+)
+
+// maxFillDays is the longest prediction shown, filesystems filling up slower are considered stable
+const maxFillDays = 365
+
+// ConfFill predicts when a filesystem is full from the samples kept in daemon mode
+type ConfFill struct {
+	// Usage percentage considered full, 100 if not set
+	FullAt int `yaml:"full_at,omitempty"`
+	// Warning if predicted to be full within this many days, disabled if 0
+	WarnDays int `yaml:"warn_days,omitempty"`
+	// Critical if predicted to be full within this many days, disabled if 0
+	CritDays int `yaml:"crit_days,omitempty"`
+}
+
+// filler is implemented by module configs embedding ConfFill
+type filler interface {
+	fill() *ConfFill
+}
+
+func (c *ConfFill) fill() *ConfFill {
+	return c
+}
+
+// target returns the usage percentage considered full
+func (c *ConfFill) target() float64 {
+	if c.FullAt == 0 {
+		return 100
+	}
+	return float64(c.FullAt)
+}
+
+// daysStatus returns the status of a filesystem predicted to be full in days
+func (c *ConfFill) daysStatus(days float64) Status {
+	if c.CritDays > 0 && days <= float64(c.CritDays) {
+		return StatusCritical
+	} else if c.WarnDays > 0 && days <= float64(c.WarnDays) {
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// validateFill checks full_at is a percentage and warn_days is more than crit_days
+func (c *ConfFill) validateFill() (errs []FieldError) {
+	if c.FullAt < 0 || c.FullAt > 100 {
+		errs = append(errs, FieldError{"full_at", fmt.Sprintf("must be a percentage, got %d", c.FullAt)})
+	}
+	if c.WarnDays < 0 {
+		errs = append(errs, FieldError{"warn_days", "cannot be negative"})
+	}
+	if c.CritDays < 0 {
+		errs = append(errs, FieldError{"crit_days", "cannot be negative"})
+	}
+	if c.WarnDays > 0 && c.CritDays > 0 && c.WarnDays <= c.CritDays {
+		errs = append(errs, FieldError{"warn_days", fmt.Sprintf("must be more than crit_days (%d), got %d", c.CritDays, c.WarnDays)})
+	}
+	return
+}
+
+// timedSamples returns the samples which have a time
+func timedSamples(samples []Sample) []Sample {
+	var timed []Sample
+	for _, s := range samples {
+		if !s.Time.IsZero() {
+			timed = append(timed, s)
+		}
+	}
+	return timed
+}
+
+// confidentPrediction reports whether there are enough samples over a long enough time for a prediction
+// from them to change the status
+func confidentPrediction(samples []Sample) bool {
+	samples = timedSamples(samples)
+	if len(samples) < minStatusSamples {
+		return false
+	}
+	return samples[len(samples)-1].Time.Sub(samples[0].Time) >= minStatusSpan
+}
+
+// DaysUntil returns the days until samples reach target using a least squares fit, false if there are
+// too few samples, they are not increasing or target was already reached. Samples without a time are ignored.
+func DaysUntil(samples []Sample, target float64) (float64, bool) {
+	samples = timedSamples(samples)
+	if len(samples) < minFillSamples {
+		return 0, false
+	}
+	t0 := samples[0].Time
+	var n, sx, sy, sxx, sxy float64
+	for _, s := range samples {
+		x := s.Time.Sub(t0).Hours() / 24
+		n++
+		sx += x
+		sy += s.Value
+		sxx += x * x
+		sxy += x * s.Value
+	}
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return 0, false
+	}
+	// Usage increase per day
+	slope := (n*sxy - sx*sy) / denom
+	remaining := target - samples[len(samples)-1].Value
+	if slope <= 0 || remaining <= 0 {
+		return 0, false
+	}
+	return remaining / slope, true
+}
+
+// formatDays formats a prediction as ~23d, or in hours if it is less than a day
+func formatDays(days float64) string {
+	if days < 1 {
+		return fmt.Sprintf("~%dh", int(math.Ceil(days*24)))
+	}
+	return fmt.Sprintf("~%dd", int(math.Round(days)))
+}
+
+// PredictFull estimates when the items of modules configured with ConfFill will be full from their samples.
+//
+// The estimate is added to their value and as a days_until_full metric, their status is raised according
+// to warn_days and crit_days once the samples are enough for a confident prediction.
+func (t *Trends) PredictFull(c *Conf, order []string, results map[string]SourceReturn) {
+	for _, k := range order {
+		sr, ok := results[k]
+		f, isFiller := c.Modules[k].(filler)
+		if !ok || !isFiller {
+			continue
+		}
+		fc := f.fill()
+		for i := range sr.Items {
+			it := &sr.Items[i]
+			if _, ok := it.Usage(); !ok || it.Name == "" {
+				continue
+			}
+			samples := t.Samples(k, it.Name)
+			days, ok := DaysUntil(samples, fc.target())
+			if !ok || days > maxFillDays {
+				continue
+			}
+			it.Value += ", full in " + formatDays(days)
+			it.Metrics = append(it.Metrics, Metric{Name: "days_until_full", Value: days})
+			if !confidentPrediction(samples) {
+				continue
+			}
+			if s := fc.daysStatus(days); s.Worse(it.Status) {
+				it.Status = s
+			}
+		}
+		sr.Status = WorstStatus(sr.Status, sr.Items)
+		results[k] = sr
+	}
+}
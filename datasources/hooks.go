@@ -0,0 +1,48 @@
+package datasources
+
+import "time"
+
+// defaultHookTimeout is used if hook_timeout is not set
+const defaultHookTimeout = time.Minute
+
+// ConfHooks are commands run in daemon mode when the status of a module or item changes
+type ConfHooks struct {
+	// Run when the status becomes warning
+	OnWarning string `yaml:"on_warning,omitempty"`
+	// Run when the status becomes critical
+	OnCritical string `yaml:"on_critical,omitempty"`
+	// Run when the status goes from warning or critical back to OK
+	OnRecover string `yaml:"on_recover,omitempty"`
+}
+
+// command returns the hook for a change from one status to another, empty if there is none
+func (c *ConfHooks) command(from Status, to Status) string {
+	switch {
+	case to == StatusCritical:
+		return c.OnCritical
+	case to == StatusWarning:
+		return c.OnWarning
+	case (from == StatusWarning || from == StatusCritical) && !to.Worse(StatusOK):
+		return c.OnRecover
+	}
+	return ""
+}
+
+// HookCommand returns the hook of module name for a change from one status to another, the global hook is used
+// unless overridden. It is empty if no hook is set.
+func (c *Conf) HookCommand(name string, from Status, to Status) string {
+	if mc, ok := c.Modules[name]; ok {
+		if cmd := mc.Base().command(from, to); cmd != "" {
+			return cmd
+		}
+	}
+	return c.ConfHooks.command(from, to)
+}
+
+// HookDeadline returns the maximum time a hook may run for, 1m if hook_timeout is not set
+func (c *ConfGlobal) HookDeadline() time.Duration {
+	if c.HookTimeout == 0 {
+		return defaultHookTimeout
+	}
+	return c.HookTimeout
+}
@@ -0,0 +1,138 @@
+package datasources
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/cosandr/go-motd/utils"
+)
+
+// Output formats
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatHTML   = "html"
+	FormatWaybar = "waybar"
+	FormatTmux   = "tmux"
+)
+
+// ValidFormat returns true if name is a known output format
+func ValidFormat(name string) bool {
+	switch name {
+	case FormatText, FormatJSON, FormatHTML, FormatWaybar, FormatTmux:
+		return true
+	}
+	return false
+}
+
+// defaultOutputMode is used for outputs without a mode
+const defaultOutputMode os.FileMode = 0644
+
+// Output is a file the rendered modules are written to in daemon mode
+type Output struct {
+	// Path to the output file, it is replaced atomically
+	Path string `yaml:"path"`
+	// Format of the output, text by default
+	Format string `yaml:"format,omitempty"`
+	// Permissions of the file as an octal string, 0644 by default
+	Mode string `yaml:"mode,omitempty"`
+	// Owner and group of the file, user name or uid and group name or gid, unchanged if empty
+	Owner string `yaml:"owner,omitempty"`
+	Group string `yaml:"group,omitempty"`
+	// Color mode, auto, always or never; the --color argument is used if empty
+	Color string `yaml:"color,omitempty"`
+}
+
+// FileFormat returns the format of the output, text if not set
+func (o *Output) FileFormat() string {
+	if o.Format == "" {
+		return FormatText
+	}
+	return o.Format
+}
+
+// FileMode returns the permissions of the output file
+func (o *Output) FileMode() (os.FileMode, error) {
+	if o.Mode == "" {
+		return defaultOutputMode, nil
+	}
+	v, err := strconv.ParseUint(o.Mode, 8, 32)
+	if err != nil || v > 0777 {
+		return 0, fmt.Errorf("invalid mode %s", o.Mode)
+	}
+	return os.FileMode(v), nil
+}
+
+// IDs returns the uid and gid of the output owner and group, -1 if they are not set
+func (o *Output) IDs() (uid int, gid int, err error) {
+	uid, gid = -1, -1
+	if o.Owner != "" {
+		u, errU := user.Lookup(o.Owner)
+		if errU != nil {
+			u, errU = user.LookupId(o.Owner)
+		}
+		if errU != nil {
+			return uid, gid, fmt.Errorf("unknown owner %s", o.Owner)
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+	if o.Group != "" {
+		g, errG := user.LookupGroup(o.Group)
+		if errG != nil {
+			g, errG = user.LookupGroupId(o.Group)
+		}
+		if errG != nil {
+			return uid, gid, fmt.Errorf("unknown group %s", o.Group)
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+	return
+}
+
+// Validate checks the path, format, color mode, file mode, owner and group
+func (o *Output) Validate() error {
+	if o.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !ValidFormat(o.FileFormat()) {
+		return fmt.Errorf("%s: unknown format %s", o.Path, o.Format)
+	}
+	if o.Color != "" && !utils.ValidColorMode(o.Color) {
+		return fmt.Errorf("%s: unknown color mode %s", o.Path, o.Color)
+	}
+	if _, err := o.FileMode(); err != nil {
+		return fmt.Errorf("%s: %v", o.Path, err)
+	}
+	if _, _, err := o.IDs(); err != nil {
+		return fmt.Errorf("%s: %v", o.Path, err)
+	}
+	return nil
+}
+
+// TemplateText returns the output template, it is read from a file unless it contains an action or a newline
+func (c *ConfGlobal) TemplateText() (string, error) {
+	if c.Template == "" || strings.Contains(c.Template, "{{") || strings.Contains(c.Template, "\n") {
+		return c.Template, nil
+	}
+	b, err := os.ReadFile(c.Template)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// validateTemplate checks the syntax of the output template, functions are checked when it is rendered
+func (c *ConfGlobal) validateTemplate() error {
+	text, err := c.TemplateText()
+	if err != nil {
+		return err
+	}
+	t := parse.New("template")
+	t.Mode = parse.SkipFuncCheck
+	_, err = t.Parse(text, "", "", make(map[string]*parse.Tree))
+	return err
+}
@@ -0,0 +1,55 @@
+package datasources
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"testing"
+)
+
+func TestOutputFileMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected os.FileMode
+		err      bool
+	}{
+		{"", 0644, false},
+		{"0600", 0600, false},
+		{"640", 0640, false},
+		{"0777", 0777, false},
+		{"1777", 0, true},
+		{"0999", 0, true},
+		{"rw-r--r--", 0, true},
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		o := Output{Path: "/tmp/motd", Mode: tt.mode}
+		mode, err := o.FileMode()
+		if (err != nil) != tt.err || mode != tt.expected {
+			t.Errorf("mode %q: got %v %v, expected %v (error %v)", tt.mode, mode, err, tt.expected, tt.err)
+		}
+	}
+}
+
+func TestOutputIDs(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	gid := strconv.Itoa(os.Getgid())
+	for _, o := range []Output{{Owner: u.Username, Group: gid}, {Owner: u.Uid, Group: gid}} {
+		uid, g, err := o.IDs()
+		if err != nil || strconv.Itoa(uid) != u.Uid || strconv.Itoa(g) != gid {
+			t.Errorf("%s:%s: got %d:%d %v, expected %s:%s", o.Owner, o.Group, uid, g, err, u.Uid, gid)
+		}
+	}
+	if uid, gid, err := (&Output{}).IDs(); uid != -1 || gid != -1 || err != nil {
+		t.Errorf("unset owner: got %d:%d %v, expected -1:-1", uid, gid, err)
+	}
+	if _, _, err := (&Output{Owner: "no-such-user-go-motd"}).IDs(); err == nil {
+		t.Errorf("expected an error for an unknown owner")
+	}
+	if _, _, err := (&Output{Group: "no-such-group-go-motd"}).IDs(); err == nil {
+		t.Errorf("expected an error for an unknown group")
+	}
+}
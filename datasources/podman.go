@@ -1,10 +1,8 @@
 package datasources
 
 import (
-	"fmt"
+	"context"
 	"os/user"
-
-	"github.com/cosandr/go-motd/utils"
 )
 
 // ConfPodman extends ConfBase with a list of containers to ignore
@@ -18,22 +16,16 @@ type ConfPodman struct {
 	Ignore []string `yaml:"ignore,omitempty"`
 }
 
-// Init sets up default alignment
-func (c *ConfPodman) Init() {
-	c.ConfBase.Init()
-	c.PadHeader[1] = 3
+func init() {
+	Register(NewDatasource("podman", "Podman", func() ConfInterface { return &ConfPodman{} }, GetPodman))
 }
 
 // GetPodman podman container status by parsing cli output
-func GetPodman(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.Podman
-	// Check for warnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetPodman(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["podman"].(*ConfPodman)
+	sr := NewSourceReturn("Podman")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	// Check if we are root
 	runningUser, err := user.Current()
@@ -43,16 +35,16 @@ func GetPodman(ch chan<- SourceReturn, conf *Conf) {
 		c.Sudo = false
 	}
 	if !c.IncludeSudo {
-		cl, err := getContainersExec(true, c.Sudo)
+		cl, err := getContainersExec(ctx, true, c.Sudo)
 		if err != nil {
-			err = &ModuleNotAvailable{"podman", err}
-			sr.Header = fmt.Sprintf("%s: %s\n", utils.Wrap("Podman", c.padL, c.padR), utils.Warn("unavailable"))
+			sr.Status = StatusUnavailable
+			sr.Error = &ModuleNotAvailable{"podman", err}
 		} else {
-			sr.Header, sr.Content, sr.Error = cl.toHeaderContent(c.Ignore, *c.WarnOnly, c.padL, c.padR)
+			cl.addTo(sr, c.Ignore)
 		}
 	} else {
-		clUser, errUser := getContainersExec(true, false)
-		clRoot, errRoot := getContainersExec(true, true)
+		clUser, errUser := getContainersExec(ctx, true, false)
+		clRoot, errRoot := getContainersExec(ctx, true, true)
 		// Combine lists for now
 		cl := containerList{Runtime: "Podman", Root: true}
 		// Add # in front of root containers
@@ -70,10 +62,13 @@ func GetPodman(ch chan<- SourceReturn, conf *Conf) {
 			})
 		}
 		if len(cl.Containers) == 0 && (errUser != nil || errRoot != nil) {
-			err = &ModuleNotAvailable{"podman", err}
-			sr.Header = fmt.Sprintf("%s: %s\n", utils.Wrap("Podman", c.padL, c.padR), utils.Warn("unavailable"))
+			if errUser == nil {
+				errUser = errRoot
+			}
+			sr.Status = StatusUnavailable
+			sr.Error = &ModuleNotAvailable{"podman", errUser}
 		} else {
-			sr.Header, sr.Content, sr.Error = cl.toHeaderContent(c.Ignore, *c.WarnOnly, c.padL, c.padR)
+			cl.addTo(sr, c.Ignore)
 		}
 	}
 }
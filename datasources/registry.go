@@ -0,0 +1,93 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Datasource is a module that can be enabled in `show_order`/`col_def` and configured in its own config section
+type Datasource interface {
+	// Name is the module name, it is used as its config key and in `show_order`/`col_def`
+	Name() string
+	// Title is the header of results which the datasource did not return itself, such as a timeout
+	Title() string
+	// NewConf returns a new, uninitialized config for this module, Init is called on it before use
+	NewConf() ConfInterface
+	// Run gathers the data and sends exactly one result to ch, it should give up once ctx is done
+	Run(ctx context.Context, ch chan<- SourceReturn, conf *Conf)
+}
+
+type funcDatasource struct {
+	name    string
+	title   string
+	newConf func() ConfInterface
+	run     func(ctx context.Context, ch chan<- SourceReturn, conf *Conf)
+}
+
+func (d *funcDatasource) Name() string {
+	return d.name
+}
+
+func (d *funcDatasource) Title() string {
+	return d.title
+}
+
+func (d *funcDatasource) NewConf() ConfInterface {
+	return d.newConf()
+}
+
+func (d *funcDatasource) Run(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	d.run(ctx, ch, conf)
+}
+
+// NewDatasource returns a Datasource built from its name, title, config factory and run function
+func NewDatasource(name string, title string, newConf func() ConfInterface, run func(ctx context.Context, ch chan<- SourceReturn, conf *Conf)) Datasource {
+	return &funcDatasource{name: name, title: title, newConf: newConf, run: run}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Datasource)
+)
+
+// Register makes a datasource available to RunSources and config parsing.
+//
+// It is meant to be called from init(), configs created before registering will not include the new module.
+// Register panics if ds is nil, its name is reserved or it is already registered.
+func Register(ds Datasource) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if ds == nil {
+		panic("datasources: Register datasource is nil")
+	}
+	name := ds.Name()
+	if name == "" || name == globalKey || name == themeKey || name == ChangesKey {
+		panic(fmt.Sprintf("datasources: invalid datasource name %q", name))
+	}
+	if _, dup := registry[name]; dup {
+		panic("datasources: Register called twice for datasource " + name)
+	}
+	registry[name] = ds
+}
+
+// Lookup returns the registered datasource called name
+func Lookup(name string) (ds Datasource, ok bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ds, ok = registry[name]
+	return
+}
+
+// Names returns a sorted list of registered datasource names
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for k := range registry {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,99 @@
+package datasources
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type confTestModule struct {
+	ConfBase `yaml:",inline"`
+	Value    int `yaml:"value"`
+}
+
+func (c *confTestModule) Init() {
+	c.ConfBase.Init()
+	c.Value = 1
+}
+
+func init() {
+	Register(NewDatasource("test_module", "Test", func() ConfInterface { return &confTestModule{} }, func(_ context.Context, ch chan<- SourceReturn, conf *Conf) {
+		c := conf.Modules["test_module"].(*confTestModule)
+		sr := NewSourceReturn("Test")
+		sr.AddItem("value", strings.Repeat("x", c.Value), StatusOK)
+		ch <- *sr
+	}))
+}
+
+func init() {
+	Register(NewDatasource("test_slow", "Slow", func() ConfInterface { return &ConfBase{} }, func(ctx context.Context, ch chan<- SourceReturn, _ *Conf) {
+		sr := NewSourceReturn("Slow")
+		<-ctx.Done()
+		ch <- sr.Return()
+	}))
+}
+
+func TestRunSourcesTimeout(t *testing.T) {
+	var c Conf
+	c.Init()
+	c.Timeout = time.Second
+	c.Modules["test_slow"].Base().Timeout = 10 * time.Millisecond
+	start := time.Now()
+	_, res := RunSources(context.Background(), []string{"test_slow", "test_module"}, &c)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("RunSources took %s", elapsed)
+	}
+	if sr := res["test_slow"]; sr.Status != StatusUnknown || sr.Message != "timed out" || sr.Error == nil || sr.Title != "Slow" {
+		t.Errorf("test_slow: got %+v, expected timed out", sr)
+	}
+	if sr := res["test_module"]; sr.Status != StatusOK {
+		t.Errorf("test_module: got %+v, expected OK", sr)
+	}
+}
+
+func TestRunSourcesReceivedBeforeDeadline(t *testing.T) {
+	var c Conf
+	c.Init()
+	// test_module finishes before its deadline, which passes while waiting for test_slow
+	c.Modules["test_module"].Base().Timeout = 5 * time.Millisecond
+	c.Modules["test_slow"].Base().Timeout = 50 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		_, res := RunSources(context.Background(), []string{"test_slow", "test_module"}, &c)
+		if sr := res["test_module"]; sr.Status != StatusOK {
+			t.Fatalf("test_module: got %+v, expected OK", sr)
+		}
+	}
+}
+
+func TestRegisteredConf(t *testing.T) {
+	var c Conf
+	c.Init()
+	in := "global:\n  col_pad: 2\ntest_module:\n  value: 3\nunknown:\n  value: 4\n"
+	if err := yaml.Unmarshal([]byte(in), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ColPad != 2 {
+		t.Errorf("col_pad: got %d, expected 2", c.ColPad)
+	}
+	if v := c.Modules["test_module"].(*confTestModule).Value; v != 3 {
+		t.Errorf("test_module value: got %d, expected 3", v)
+	}
+	// Other modules should keep their defaults
+	if v := c.Modules["cpu"].(*ConfTempCPU).Warn; v != 70 {
+		t.Errorf("cpu warn: got %d, expected 70", v)
+	}
+	out, err := yaml.Marshal(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), "global:\n") || !strings.Contains(string(out), "test_module:\n") {
+		t.Errorf("unexpected dump:\n%s", out)
+	}
+	order, res := RunSources(context.Background(), []string{"test_module", "missing"}, &c)
+	if len(order) != 1 || res["test_module"].Items[0].Value != "xxx" {
+		t.Errorf("RunSources: got %v %v", order, res)
+	}
+}
@@ -0,0 +1,119 @@
+package datasources
+
+import "fmt"
+
+// Status is the state of a module or one of its items, higher values are worse
+type Status int
+
+const (
+	// StatusInfo is an informational value which is neither good nor bad
+	StatusInfo Status = iota
+	// StatusOK everything is fine
+	StatusOK
+	// StatusUnavailable the data could not be gathered, for example a missing command
+	StatusUnavailable
+	// StatusUnknown the data was gathered but the state could not be determined
+	StatusUnknown
+	// StatusWarning the warning threshold was reached
+	StatusWarning
+	// StatusCritical the critical threshold was reached or something failed
+	StatusCritical
+)
+
+var statusNames = map[Status]string{
+	StatusInfo:        "info",
+	StatusOK:          "ok",
+	StatusUnavailable: "unavailable",
+	StatusUnknown:     "unknown",
+	StatusWarning:     "warning",
+	StatusCritical:    "critical",
+}
+
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// MarshalText encodes the status as its name
+func (s Status) MarshalText() ([]byte, error) {
+	if _, ok := statusNames[s]; !ok {
+		return nil, fmt.Errorf("unknown status %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a status from its name
+func (s *Status) UnmarshalText(text []byte) error {
+	for k, v := range statusNames {
+		if v == string(text) {
+			*s = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown status %q", text)
+}
+
+// Worse returns true if s is worse than other
+func (s Status) Worse(other Status) bool {
+	return s > other
+}
+
+// WorstStatus returns the worst status of all items, or `min` if they are all better
+func WorstStatus(min Status, items []Item) Status {
+	worst := min
+	for _, it := range items {
+		if it.Status.Worse(worst) {
+			worst = it.Status
+		}
+	}
+	return worst
+}
+
+// Item is a single entry of a module, for example a container, a disk or a filesystem
+type Item struct {
+	// Name of the entry, may be empty for free-form values
+	Name string `json:"name"`
+	// Value as shown to the user, without unit
+	Value string `json:"value"`
+	// Unit of Value, empty if not applicable
+	Unit string `json:"unit,omitempty"`
+	// Status of this entry
+	Status Status `json:"status"`
+	// Numeric measurements of this entry
+	Metrics []Metric `json:"metrics,omitempty"`
+	// Recent samples of the main metric from oldest to newest, only kept in daemon mode
+	Trend []float64 `json:"trend,omitempty"`
+}
+
+// Usage returns the usage percentage of the item, see usageMetric
+func (it *Item) Usage() (Metric, bool) {
+	return usageMetric(it.Metrics)
+}
+
+// usageMetric returns the first metric with a % unit and a maximum
+func usageMetric(metrics []Metric) (Metric, bool) {
+	for _, m := range metrics {
+		if m.Unit == "%" && m.Max > 0 {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
+
+// Metric is a numeric measurement, thresholds and maximum are zero if not applicable
+type Metric struct {
+	// Name of the measurement including its unit, for example used_bytes
+	Name string `json:"name"`
+	// Value of the measurement
+	Value float64 `json:"value"`
+	// Unit as used in Nagios performance data, empty, % or B
+	Unit string `json:"unit,omitempty"`
+	// Warning threshold
+	Warn float64 `json:"warn,omitempty"`
+	// Critical threshold
+	Crit float64 `json:"crit,omitempty"`
+	// Maximum possible value
+	Max float64 `json:"max,omitempty"`
+}
@@ -3,6 +3,7 @@ package datasources
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,62 +11,94 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/cosandr/go-motd/utils"
+	log "github.com/sirupsen/logrus"
 )
 
+// ConfSysInfo warn and crit only apply to the RAM usage bar
 type ConfSysInfo struct {
-	ConfBase `yaml:",inline"`
+	ConfBaseWarn `yaml:",inline"`
 }
 
-func (c *ConfSysInfo) Init() {
-	c.ConfBase.Init()
-	c.PadHeader = []int{0, 3}
-	c.PadContent = []int{0, 0}
+func init() {
+	Register(NewDatasource("sysinfo", "", func() ConfInterface { return &ConfSysInfo{} }, GetSysInfo))
 }
 
 // GetSysInfo various stats about the host Linux OS (kernel, distro, load and more)
-func GetSysInfo(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.SysInfo
-	sr := NewSourceReturn(conf.debug)
+func GetSysInfo(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["sysinfo"].(*ConfSysInfo)
+	sr := NewSourceReturn("")
+	sr.Status = StatusInfo
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	type entry struct {
-		name    string
-		content string
+		name string
+		get  func(ctx context.Context) (string, error)
 	}
 	// Fetch all the things
 	var info = [...]entry{
-		{"Distro", getDistroName()},
-		{"Kernel", getKernel()},
-		{"Uptime", getUptime()},
-		{"Load", getLoadAvg()},
-		{"RAM", getMemoryInfo()},
+		{"Distro", getDistroName},
+		{"Kernel", getKernel},
+		{"Uptime", getUptime},
 	}
 	for _, e := range info {
-		sr.Header += fmt.Sprintf("%s: %s\n", utils.Wrap(e.name, c.padL, c.padR), e.content)
+		value, err := e.get(ctx)
+		if err != nil {
+			log.Debugf("[sysinfo] %s: %v", e.name, err)
+			sr.AddItem(e.name, "unavailable", StatusUnavailable)
+		} else {
+			sr.AddItem(e.name, value, StatusInfo)
+		}
+	}
+	sr.Items = append(sr.Items, loadItem(), memoryItem(&c))
+}
+
+// loadItem returns the load averages, the 1 minute average is also a metric
+func loadItem() Item {
+	load, err := getLoadAvg()
+	if err != nil {
+		log.Debugf("[sysinfo] Load: %v", err)
+		return Item{Name: "Load", Value: "unavailable", Status: StatusUnavailable}
+	}
+	load1, _ := strconv.ParseFloat(load[0], 64)
+	return Item{
+		Name:    "Load",
+		Value:   fmt.Sprintf("%s [1m], %s [5m], %s [15m]", load[0], load[1], load[2]),
+		Status:  StatusInfo,
+		Metrics: []Metric{{Name: "load1", Value: load1}},
+	}
+}
+
+// memoryItem returns the active and total memory with its usage compared to the thresholds
+func memoryItem(c *ConfSysInfo) Item {
+	memActive, memTotal, err := getMemoryInfo()
+	if err != nil {
+		log.Debugf("[sysinfo] RAM: %v", err)
+		return Item{Name: "RAM", Value: "unavailable", Status: StatusUnavailable}
+	}
+	// Convert to GB, meminfo is in kB
+	return Item{
+		Name:    "RAM",
+		Value:   fmt.Sprintf("%.2f GB active of %.2f GB", memActive/1e6, memTotal/1e6),
+		Status:  StatusInfo,
+		Metrics: usageMetrics(&c.ConfBaseWarn, memActive*1e3, memTotal*1e3),
 	}
 }
 
 // runCmd executes command and returns stdout as string
-func runCmd(name string, args string, buf *bytes.Buffer) (string, error) {
-	var retStr string
-	cmd := exec.Command(name, args)
+func runCmd(ctx context.Context, name string, args string, buf *bytes.Buffer) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args)
 	cmd.Stdout = buf
-	err := cmd.Run()
-	if err != nil {
-		retStr = utils.Warn("unavailable")
-	} else {
-		retStr = buf.String()
+	defer buf.Reset()
+	if err := cmd.Run(); err != nil {
+		return "", err
 	}
-	buf.Reset()
-	return retStr, err
+	return buf.String(), nil
 }
 
-func getDistroName() (retStr string) {
+func getDistroName(_ context.Context) (retStr string, err error) {
 	file, err := os.Open("/etc/os-release")
 	if err != nil {
-		retStr = utils.Warn("unavailable")
 		return
 	}
 	defer file.Close()
@@ -81,44 +114,43 @@ func getDistroName() (retStr string) {
 			return
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		retStr = utils.Warn("unavailable")
-		return
-	}
+	err = scanner.Err()
 	return
 }
 
-func getUptime() string {
+func getUptime(ctx context.Context) (string, error) {
 	var buf bytes.Buffer
-	uptime, err := runCmd("uptime", "-p", &buf)
+	uptime, err := runCmd(ctx, "uptime", "-p", &buf)
 	if err != nil {
-		return uptime
+		return "", err
 	}
 	re := regexp.MustCompile(`(up\s|\n)`)
-	return re.ReplaceAllString(uptime, "")
+	return re.ReplaceAllString(uptime, ""), nil
 }
 
-func getLoadAvg() string {
+// getLoadAvg returns the 1, 5 and 15 minute load averages
+func getLoadAvg() ([]string, error) {
 	loadavg, err := os.ReadFile("/proc/loadavg")
 	if err != nil {
-		return utils.Warn("unavailable")
+		return nil, err
 	}
 	var loadArr = strings.Split(string(loadavg), " ")
-	return fmt.Sprintf("%s [1m], %s [5m], %s [15m]", loadArr[0], loadArr[1], loadArr[2])
+	if len(loadArr) < 3 {
+		return nil, fmt.Errorf("cannot parse %q", loadavg)
+	}
+	return loadArr[:3], nil
 }
 
-func getMemoryInfo() (retStr string) {
+// getMemoryInfo returns active and total memory in kB
+func getMemoryInfo() (memActive float64, memTotal float64, err error) {
 	file, err := os.Open("/proc/meminfo")
 	if err != nil {
-		retStr = utils.Warn("unavailable")
 		return
 	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
 	// Look for active and total
-	var memActive float64
-	var memTotal float64
 	reActive := regexp.MustCompile(`Active:\s+(\d+)`)
 	reTotal := regexp.MustCompile(`MemTotal:\s+(\d+)`)
 	for scanner.Scan() {
@@ -141,16 +173,14 @@ func getMemoryInfo() (retStr string) {
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		retStr = utils.Warn("unavailable")
-		return
+	if err = scanner.Err(); err == nil && memTotal == 0 {
+		err = fmt.Errorf("MemTotal not found")
 	}
-	// Convert to GB, meminfo is in kB
-	return fmt.Sprintf("%.2f GB active of %.2f GB", memActive/1e6, memTotal/1e6)
+	return
 }
 
-func getKernel() string {
+func getKernel(ctx context.Context) (string, error) {
 	var buf bytes.Buffer
-	var kernel, _ = runCmd("uname", "-sr", &buf)
-	return strings.ReplaceAll(kernel, "\n", "")
+	kernel, err := runCmd(ctx, "uname", "-sr", &buf)
+	return strings.ReplaceAll(kernel, "\n", ""), err
 }
@@ -1,13 +1,13 @@
 package datasources
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 
 	"github.com/coreos/go-systemd/v22/dbus"
-	"github.com/cosandr/go-motd/utils"
 )
 
 // ConfSystemd extends ConfBase with a list of units to monitor
@@ -26,7 +26,6 @@ type ConfSystemd struct {
 // Init sets ShowFailed to true
 func (c *ConfSystemd) Init() {
 	c.ConfBase.Init()
-	c.PadHeader[1] = 2
 	c.ShowFailed = true
 }
 
@@ -44,12 +43,12 @@ func (s *systemdUnit) IsEmpty() bool {
 }
 
 // GetProperties gets this unit's properties from DBus
-func (s *systemdUnit) GetProperties(con *dbus.Conn) (err error) {
+func (s *systemdUnit) GetProperties(ctx context.Context, con *dbus.Conn) (err error) {
 	// Do nothing if we already have everything
 	if s.ActiveState != "" && s.Result != "" && s.ExecMainStatus != "" && s.LoadState != "" {
 		return
 	}
-	props, err := con.GetUnitProperties(s.Name)
+	props, err := con.GetUnitPropertiesContext(ctx, s.Name)
 	if err != nil {
 		return
 	}
@@ -76,37 +75,40 @@ func (s *systemdUnit) GetProperties(con *dbus.Conn) (err error) {
 	return
 }
 
+func init() {
+	Register(NewDatasource("systemd", "Systemd", func() ConfInterface { return &ConfSystemd{} }, GetSystemd))
+}
+
 // GetSystemd gets systemd unit status using dbus
-func GetSystemd(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.Systemd
-	// Check for *c.WarnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetSystemd(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["systemd"].(*ConfSystemd)
+	sr := NewSourceReturn("Systemd")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
-	sr.Header, sr.Content, sr.Error = getServiceStatus(&c)
+	sr.Error = getServiceStatus(ctx, &c, sr)
 }
 
 // getServiceStatus get service properties
-func getServiceStatus(c *ConfSystemd) (header string, content string, err error) {
-	con, err := dbus.New()
+func getServiceStatus(ctx context.Context, c *ConfSystemd, sr *SourceReturn) (err error) {
+	con, err := dbus.NewWithContext(ctx)
 	if err != nil {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Systemd", c.padL, c.padR), utils.Err("DBus failed"))
+		sr.Status = StatusUnavailable
+		sr.Message = "DBus failed"
+		err = &ModuleNotAvailable{"systemd", err}
 		return
 	}
 	defer con.Close()
 	// No units to check and didn't request to show failed
 	if len(c.Units) == 0 && !c.ShowFailed {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Systemd", c.padL, c.padR), utils.Warn("unconfigured"))
+		sr.Status = StatusInfo
+		sr.Message = "unconfigured"
 		return
 	}
 	units := make([]systemdUnit, 0)
 	if c.ShowFailed {
 		// Get all failed
-		listFailed, _ := con.ListUnitsFiltered([]string{"failed"})
+		listFailed, _ := con.ListUnitsFilteredContext(ctx, []string{"failed"})
 		if len(listFailed) > 0 {
 			for _, u := range listFailed {
 				units = append(units, systemdUnit{
@@ -124,78 +126,56 @@ func getServiceStatus(c *ConfSystemd) (header string, content string, err error)
 			})
 		}
 	}
-	var errStr = ""
 	// Get missing properties
+	var propErrors []Item
 	for i := range units {
-		err = units[i].GetProperties(con)
+		err = units[i].GetProperties(ctx, con)
 		if err != nil {
-			errStr += fmt.Sprintf("Failed to get properties for %s: %s\n", units[i].Name, err)
+			propErrors = append(propErrors, Item{
+				Name:   units[i].Name,
+				Value:  fmt.Sprintf("failed to get properties: %v", err),
+				Status: StatusUnknown,
+			})
 			err = nil
 		}
 	}
-	// Map of maps to hold properties
 	sort.Slice(units, func(i, j int) bool {
 		return units[i].Name < units[j].Name
 	})
-	// Maps to make checking easier later
-	var failedUnits = map[string]string{}
-	var goodUnits = map[string]string{}
+	// Remove all systemd extensions
+	reExt := regexp.MustCompile(`(\.service|\.socket|\.device|\.mount|\.automount|\.swap|\.target|\.path|\.timer|\.slice|\.scope)`)
 	// Loop through units so it is alphabetical
 	for _, u := range units {
 		// Skip if we have no stats
 		if u.IsEmpty() {
 			continue
 		}
-		wrapped := utils.Wrap(u.Name, c.padL, c.padR)
+		name := u.Name
 		if c.HideExt {
-			// Remove all systemd extensions
-			re := regexp.MustCompile(`(\.service|\.socket|\.device|\.mount|\.automount|\.swap|\.target|\.path|\.timer|\.slice|\.scope)`)
-			wrapped = re.ReplaceAllString(wrapped, "")
+			name = reExt.ReplaceAllString(name, "")
 		}
+		var item Item
 		// No such unit file
 		if u.LoadState != "loaded" {
-			failedUnits[u.Name] = fmt.Sprintf("%s: %s\n", wrapped, utils.Err(u.LoadState))
-		} else {
+			item = Item{Name: name, Value: u.LoadState, Status: StatusCritical}
+		} else if u.ActiveState == "active" {
 			// Service running
-			if u.ActiveState == "active" {
-				goodUnits[u.Name] = fmt.Sprintf("%s: %s\n", wrapped, utils.Good(u.ActiveState))
+			item = Item{Name: name, Value: u.ActiveState, Status: StatusOK}
+		} else if u.ExecMainStatus == "0" {
+			// Not running but existed successfully
+			if c.InactiveOK {
+				item = Item{Name: name, Value: u.Result, Status: StatusOK}
 			} else {
-				// Not running but existed successfully
-				if u.ExecMainStatus == "0" {
-					if c.InactiveOK {
-						goodUnits[u.Name] = fmt.Sprintf("%s: %s\n", wrapped, utils.Good(u.Result))
-					} else {
-						failedUnits[u.Name] = fmt.Sprintf("%s: %s\n", wrapped, utils.Warn(u.ActiveState))
-					}
-					// Not running and failed
-				} else {
-					failedUnits[u.Name] = fmt.Sprintf("%s: %s\n", wrapped, utils.Err(u.ActiveState))
-				}
+				item = Item{Name: name, Value: u.ActiveState, Status: StatusWarning}
 			}
+		} else {
+			// Not running and failed
+			item = Item{Name: name, Value: u.ActiveState, Status: StatusCritical}
 		}
+		sr.Items = append(sr.Items, item)
 	}
-	// Decide what header should be
-	// Only print all services if requested
-	if len(goodUnits) == 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Systemd", c.padL, c.padR), utils.Err("critical"))
-	} else if len(failedUnits) == 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Systemd", c.padL, c.padR), utils.Good("OK"))
-		if *c.WarnOnly {
-			return
-		}
-	} else if len(failedUnits) < len(units) {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Systemd", c.padL, c.padR), utils.Warn("warning"))
-	}
-	// Print all in order
-	for _, u := range units {
-		if val, ok := goodUnits[u.Name]; ok && !*c.WarnOnly {
-			content += val
-		} else if val, ok := failedUnits[u.Name]; ok {
-			content += val
-		}
-	}
-	if len(errStr) > 0 {
-		content += errStr
-	}
+	sr.Items = append(sr.Items, propErrors...)
+	// No failed units is OK even if there are none to show, units without properties are unknown
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 	return
 }
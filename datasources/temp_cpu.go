@@ -1,14 +1,14 @@
 package datasources
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 
 	"github.com/shirou/gopsutil/v3/host"
 	log "github.com/sirupsen/logrus"
-
-	"github.com/cosandr/go-motd/utils"
 )
 
 // ConfTempCPU extends ConfBase with a list of containers to ignore
@@ -18,43 +18,40 @@ type ConfTempCPU struct {
 	Exec bool `yaml:"use_exec"`
 }
 
-// Init sets up default alignment
-func (c *ConfTempCPU) Init() {
-	c.ConfBaseWarn.Init()
-	c.PadHeader[1] = 1
+func init() {
+	Register(NewDatasource("cpu", "CPU temp", func() ConfInterface { return &ConfTempCPU{} }, GetCPUTemp))
 }
 
 // GetCPUTemp returns CPU core temps using gopsutil or parsing sensors output
-func GetCPUTemp(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.CPU
-	// Check for warnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetCPUTemp(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["cpu"].(*ConfTempCPU)
+	sr := NewSourceReturn("CPU temp")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	var tempMap map[string]int
 	var isZen bool
 	var err error
 	if c.Exec {
-		tempMap, isZen, err = cpuTempSensors()
+		tempMap, isZen, err = cpuTempSensors(ctx)
 	} else {
-		tempMap, isZen, err = cpuTempGopsutil()
+		tempMap, isZen, err = cpuTempGopsutil(ctx)
 	}
 	if err != nil {
 		log.Warnf("[cpu] temperature read error: %v", err)
 	}
 	if len(tempMap) == 0 {
-		err = &ModuleNotAvailable{"cpu", err}
-		sr.Header = fmt.Sprintf("%s: %s\n", utils.Wrap("CPU temp", c.padL, c.padR), utils.Warn("unavailable"))
+		if err == nil {
+			err = fmt.Errorf("no CPU temperatures found")
+		}
+		sr.Status = StatusUnavailable
+		sr.Error = &ModuleNotAvailable{"cpu", err}
 	} else {
-		sr.Header, sr.Content, sr.Error = formatCPUTemps(tempMap, isZen, &c)
+		formatCPUTemps(tempMap, isZen, &c, sr)
 	}
 }
 
-func formatCPUTemps(tempMap map[string]int, isZen bool, c *ConfTempCPU) (header string, content string, err error) {
+func formatCPUTemps(tempMap map[string]int, isZen bool, c *ConfTempCPU, sr *SourceReturn) {
 	// Sort keys
 	sortedNames := make([]string, len(tempMap))
 	i := 0
@@ -63,39 +60,25 @@ func formatCPUTemps(tempMap map[string]int, isZen bool, c *ConfTempCPU) (header
 		i++
 	}
 	sort.Strings(sortedNames)
-	var warnCount int
-	var errCount int
 	for _, k := range sortedNames {
 		v := tempMap[k]
-		var wrapped string
+		name := k
 		if !isZen {
-			wrapped = utils.Wrap(fmt.Sprintf("Core %s", k), c.padL, c.padR)
-		} else {
-			wrapped = utils.Wrap(k, c.padL, c.padR)
+			name = fmt.Sprintf("Core %s", k)
 		}
-		if v < c.Warn && !*c.WarnOnly {
-			content += fmt.Sprintf("%s: %s\n", wrapped, utils.Good(v))
-		} else if v >= c.Warn && v < c.Crit {
-			content += fmt.Sprintf("%s: %s\n", wrapped, utils.Warn(v))
-			warnCount++
-		} else if v >= c.Crit {
-			warnCount++
-			errCount++
-			content += fmt.Sprintf("%s: %s\n", wrapped, utils.Err(v))
-		}
-	}
-	if warnCount == 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("CPU temp", c.padL, c.padR), utils.Good("OK"))
-	} else if errCount > 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("CPU temp", c.padL, c.padR), utils.Err("Critical"))
-	} else if warnCount > 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("CPU temp", c.padL, c.padR), utils.Warn("Warning"))
+		sr.Items = append(sr.Items, Item{
+			Name:    name,
+			Value:   strconv.Itoa(v),
+			Unit:    "°C",
+			Status:  c.Status(v),
+			Metrics: []Metric{c.Metric("temperature_celsius", float64(v))},
+		})
 	}
-	return
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 }
 
-func cpuTempGopsutil() (tempMap map[string]int, isZen bool, err error) {
-	temps, err := host.SensorsTemperatures()
+func cpuTempGopsutil(ctx context.Context) (tempMap map[string]int, isZen bool, err error) {
+	temps, err := host.SensorsTemperaturesWithContext(ctx)
 	tempMap = make(map[string]int)
 	addTemp := func(re *regexp.Regexp) {
 		for _, stat := range temps {
@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"os/exec"
 	"regexp"
@@ -9,9 +10,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func cpuTempSensors() (tempMap map[string]int, isZen bool, err error) {
+func cpuTempSensors(ctx context.Context) (tempMap map[string]int, isZen bool, err error) {
 	var buf bytes.Buffer
-	cmd := exec.Command("sensors", "-j")
+	cmd := exec.CommandContext(ctx, "sensors", "-j")
 	cmd.Stdout = &buf
 	err = cmd.Run()
 	if err != nil {
@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"strconv"
@@ -37,35 +38,34 @@ func (c *ConfTempDisk) Init() {
 	c.Crit = 50
 }
 
+func init() {
+	Register(NewDatasource("disk", "Disk temp", func() ConfInterface { return &ConfTempDisk{} }, GetDiskTemps))
+}
+
 // GetDiskTemps returns disk temperatures using hddtemp daemon or drivetemp kernel driver
-func GetDiskTemps(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.Disk
-	// Check for *c.WarnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetDiskTemps(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["disk"].(*ConfTempDisk)
+	sr := NewSourceReturn("Disk temp")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	var diskEntries []diskEntry
 	var err error
 	if c.Sys {
 		diskEntries, err = getFromSys()
 	} else {
-		diskEntries, err = getFromHddtemp()
+		diskEntries, err = getFromHddtemp(ctx)
 	}
 	if err != nil {
-		err = &ModuleNotAvailable{"disk", err}
-		sr.Header = fmt.Sprintf("%s: %s\n", utils.Wrap("Disk temp", c.padL, c.padR), utils.Warn("unavailable"))
+		sr.Status = StatusUnavailable
+		sr.Error = &ModuleNotAvailable{"disk", err}
 	} else {
-		sr.Header, sr.Content, sr.Error = formatDiskEntries(diskEntries, &c)
+		formatDiskEntries(diskEntries, &c, sr)
 	}
 }
 
-func formatDiskEntries(diskEntries []diskEntry, c *ConfTempDisk) (header string, content string, err error) {
-	var numNotOK uint8
-	var numTotal uint8
+// formatDiskEntries adds an item for every temperature, disks without a readable temperature are unknown
+func formatDiskEntries(diskEntries []diskEntry, c *ConfTempDisk, sr *SourceReturn) {
 	// Make set of ignored devices
 	var ignoreSet utils.StringSet
 	ignoreSet = ignoreSet.FromList(c.Ignore)
@@ -74,8 +74,7 @@ func formatDiskEntries(diskEntries []diskEntry, c *ConfTempDisk) (header string,
 			continue
 		}
 		if len(entry.temps) == 0 {
-			content += fmt.Sprintf("%s: %s\n", utils.Wrap(entry.block, c.padL, c.padR), utils.Err("--"))
-			numNotOK++
+			sr.AddItem(entry.block, "--", StatusUnknown)
 			continue
 		}
 		for _, t := range entry.temps {
@@ -84,34 +83,28 @@ func formatDiskEntries(diskEntries []diskEntry, c *ConfTempDisk) (header string,
 			if len(t.name) > 0 {
 				diskName += fmt.Sprintf(" - %s", t.name)
 			}
-			if temp < c.Warn && !*c.WarnOnly {
-				content += fmt.Sprintf("%s: %s\n", utils.Wrap(diskName, c.padL, c.padR), utils.Good(temp))
-			} else if temp >= c.Warn && temp < c.Crit {
-				content += fmt.Sprintf("%s: %s\n", utils.Wrap(diskName, c.padL, c.padR), utils.Warn(temp))
-				numNotOK++
-			} else if temp >= c.Crit {
-				content += fmt.Sprintf("%s: %s\n", utils.Wrap(diskName, c.padL, c.padR), utils.Err(temp))
-				numNotOK++
-			}
-			numTotal++
+			sr.Items = append(sr.Items, Item{
+				Name:    diskName,
+				Value:   strconv.Itoa(temp),
+				Unit:    "°C",
+				Status:  c.Status(temp),
+				Metrics: []Metric{c.Metric("temperature_celsius", t.temp)},
+			})
 		}
 	}
-	if numNotOK == 0 {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Disk temp", c.padL, c.padR), utils.Good("OK"))
-	} else if numNotOK < numTotal {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Disk temp", c.padL, c.padR), utils.Warn("Warning"))
-	} else {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Disk temp", c.padL, c.padR), utils.Err("Critical"))
-	}
-	return
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 }
 
-func getFromHddtemp() (deviceList []diskEntry, err error) {
-	conn, err := net.Dial("tcp", "127.0.0.1:7634")
+func getFromHddtemp(ctx context.Context) (deviceList []diskEntry, err error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", "127.0.0.1:7634")
 	if err != nil {
 		return
 	}
 	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
 	message, err := bufio.NewReader(conn).ReadString('\n')
 	if len(message) == 0 {
 		err = fmt.Errorf("no response from hddtemp")
@@ -123,10 +116,10 @@ func getFromHddtemp() (deviceList []diskEntry, err error) {
 	for _, line := range strings.Split(message, "||") {
 		line = strings.TrimPrefix(line, "|")
 		tmp := strings.Split(line, "|")
-		if tmp[2] == "NA" {
+		// Disks which are asleep or have no sensor report NA
+		if temp, errP := strconv.ParseFloat(tmp[2], 64); errP != nil {
 			temps = nil
 		} else {
-			temp, _ := strconv.ParseFloat(tmp[2], 64)
 			temps = []diskTemp{{name: "sensor", temp: temp}}
 		}
 		block := strings.TrimPrefix(tmp[0], "/dev/")
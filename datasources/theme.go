@@ -0,0 +1,84 @@
+package datasources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cosandr/go-motd/utils"
+)
+
+// themeKey is the config section for colors, it cannot be used as a datasource name
+const themeKey = "theme"
+
+// ConfTheme is the config struct for colors, the styles are accepted by utils.ParseStyle
+type ConfTheme struct {
+	// Built-in theme, the other keys override its styles
+	Preset string `yaml:"preset"`
+	Good   string `yaml:"good,omitempty"`
+	Warn   string `yaml:"warn,omitempty"`
+	Err    string `yaml:"err,omitempty"`
+	Info   string `yaml:"info,omitempty"`
+	Label  string `yaml:"label,omitempty"`
+	Muted  string `yaml:"muted,omitempty"`
+}
+
+// Init sets the default preset
+func (c *ConfTheme) Init() {
+	c.Preset = "default"
+}
+
+// Spec returns the preset with the configured styles applied
+func (c *ConfTheme) Spec() (utils.ThemeSpec, error) {
+	spec, ok := utils.ThemePresets[c.Preset]
+	if !ok {
+		return spec, fmt.Errorf("unknown preset %s, available: %s", c.Preset, strings.Join(utils.PresetNames(), ", "))
+	}
+	for _, f := range []struct {
+		value string
+		spec  *string
+	}{
+		{c.Good, &spec.Good},
+		{c.Warn, &spec.Warn},
+		{c.Err, &spec.Err},
+		{c.Info, &spec.Info},
+		{c.Label, &spec.Label},
+		{c.Muted, &spec.Muted},
+	} {
+		if f.value != "" {
+			*f.spec = f.value
+		}
+	}
+	return spec, nil
+}
+
+// Theme returns the parsed theme
+func (c *ConfTheme) Theme() (utils.Theme, error) {
+	spec, err := c.Spec()
+	if err != nil {
+		return utils.Theme{}, err
+	}
+	return spec.Parse()
+}
+
+// Validate checks the preset and every style
+func (c *ConfTheme) Validate() (errs []FieldError) {
+	if _, err := c.Spec(); err != nil {
+		errs = append(errs, FieldError{"preset", err.Error()})
+	}
+	for _, f := range []struct {
+		key   string
+		value string
+	}{
+		{"good", c.Good},
+		{"warn", c.Warn},
+		{"err", c.Err},
+		{"info", c.Info},
+		{"label", c.Label},
+		{"muted", c.Muted},
+	} {
+		if _, err := utils.ParseStyle(f.value); err != nil {
+			errs = append(errs, FieldError{f.key, err.Error()})
+		}
+	}
+	return
+}
@@ -0,0 +1,138 @@
+package datasources
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cosandr/go-motd/utils"
+)
+
+// Sample is a measurement at a point in time
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// UnmarshalJSON decodes a sample, a bare number is a sample without a time as saved by earlier versions
+func (s *Sample) UnmarshalJSON(b []byte) error {
+	var v float64
+	if err := json.Unmarshal(b, &v); err == nil {
+		*s = Sample{Value: v}
+		return nil
+	}
+	// Avoid recursing into this method
+	type sample Sample
+	return json.Unmarshal(b, (*sample)(s))
+}
+
+// trendMetric returns the metric which is sampled for trends, the usage percentage if there is one, otherwise the first metric
+func trendMetric(metrics []Metric) (Metric, bool) {
+	if m, ok := usageMetric(metrics); ok {
+		return m, true
+	}
+	if len(metrics) > 0 {
+		return metrics[0], true
+	}
+	return Metric{}, false
+}
+
+// Trends keeps the recent samples of every module and item with metrics across refreshes
+type Trends struct {
+	size int
+	// Samples keyed by module or module/item
+	samples map[string]*utils.Ring[Sample]
+}
+
+// NewTrends returns empty trends keeping size samples of each module and item
+func NewTrends(size int) *Trends {
+	return &Trends{size: size, samples: make(map[string]*utils.Ring[Sample])}
+}
+
+// Resize changes the number of samples kept, the most recent ones are kept if it is smaller
+func (t *Trends) Resize(size int) {
+	if size == t.size {
+		return
+	}
+	t.size = size
+	for k, r := range t.samples {
+		t.samples[k] = r.Resize(size)
+	}
+}
+
+// Samples returns the samples of module, or of its item if it is not empty, from oldest to newest
+func (t *Trends) Samples(module string, item string) []Sample {
+	key := module
+	if item != "" {
+		key += "/" + item
+	}
+	if r, ok := t.samples[key]; ok {
+		return r.Values()
+	}
+	return nil
+}
+
+// add appends a sample to key and returns the values of its samples
+func (t *Trends) add(key string, s Sample) []float64 {
+	r, ok := t.samples[key]
+	if !ok {
+		r = utils.NewRing[Sample](t.size)
+		t.samples[key] = r
+	}
+	r.Add(s)
+	samples := r.Values()
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value
+	}
+	return values
+}
+
+// Record adds a sample taken at now for every module and item with metrics and sets their trend.
+//
+// Samples of items which are gone are dropped, modules which did not run or did not return their data are kept
+// as they are.
+func (t *Trends) Record(now time.Time, order []string, results map[string]SourceReturn) {
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok || !sr.HasData() {
+			continue
+		}
+		seen := make(map[string]bool)
+		if m, ok := trendMetric(sr.Metrics); ok {
+			seen[k] = true
+			sr.Trend = t.add(k, Sample{now, m.Value})
+		}
+		for i, it := range sr.Items {
+			if m, ok := trendMetric(it.Metrics); ok && it.Name != "" {
+				key := k + "/" + it.Name
+				seen[key] = true
+				sr.Items[i].Trend = t.add(key, Sample{now, m.Value})
+			}
+		}
+		for key := range t.samples {
+			if (key == k || strings.HasPrefix(key, k+"/")) && !seen[key] {
+				delete(t.samples, key)
+			}
+		}
+		results[k] = sr
+	}
+}
+
+// MarshalJSON encodes the samples of every module and item from oldest to newest
+func (t *Trends) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.samples)
+}
+
+// UnmarshalJSON decodes samples encoded by MarshalJSON, they are resized to the current size
+func (t *Trends) UnmarshalJSON(b []byte) error {
+	samples := make(map[string]*utils.Ring[Sample])
+	if err := json.Unmarshal(b, &samples); err != nil {
+		return err
+	}
+	t.samples = samples
+	for k, r := range t.samples {
+		t.samples[k] = r.Resize(t.size)
+	}
+	return nil
+}
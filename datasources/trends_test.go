@@ -0,0 +1,112 @@
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrendsRecord(t *testing.T) {
+	trends := NewTrends(2)
+	run := func(temps ...float64) map[string]SourceReturn {
+		sr := SourceReturn{Status: StatusOK, Metrics: []Metric{{Name: "running", Value: float64(len(temps))}}}
+		for i, v := range temps {
+			sr.Items = append(sr.Items, Item{Name: string(rune('a' + i)), Metrics: []Metric{
+				{Name: "used_bytes", Value: 1},
+				{Name: "used_percent", Value: v, Unit: "%", Max: 100},
+			}})
+		}
+		results := map[string]SourceReturn{"zfs": sr}
+		trends.Record(time.Now(), []string{"zfs"}, results)
+		return results
+	}
+	run(10, 20)
+	run(11, 21)
+	results := run(12)
+	if actual, expected := results["zfs"].Items[0].Trend, []float64{11, 12}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("item trend: got %v, expected %v", actual, expected)
+	}
+	if actual, expected := results["zfs"].Trend, []float64{2, 1}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("module trend: got %v, expected %v", actual, expected)
+	}
+	if _, ok := trends.samples["zfs/b"]; ok {
+		t.Errorf("samples of removed item were kept")
+	}
+	// A module which timed out keeps its samples
+	timedOut := map[string]SourceReturn{"zfs": {Status: StatusUnknown, Error: context.DeadlineExceeded}}
+	trends.Record(time.Now(), []string{"zfs"}, timedOut)
+	if actual, expected := trends.Samples("zfs", "a"), 2; len(actual) != expected {
+		t.Errorf("samples after a timeout: got %v, expected %d", actual, expected)
+	}
+}
+
+func TestPredictFull(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	trends := NewTrends(10)
+	c := &Conf{Modules: map[string]ConfInterface{"zfs": &ConfZFS{ConfFill: ConfFill{WarnDays: 30, CritDays: 7}}}}
+	run := func(day int, used float64) SourceReturn {
+		results := map[string]SourceReturn{"zfs": {Status: StatusOK, Items: []Item{{
+			Name: "tank", Value: fmt.Sprintf("%g%% used", used), Status: StatusOK,
+			Metrics: []Metric{{Name: "used_percent", Value: used, Unit: "%", Max: 100}},
+		}}}}
+		trends.Record(now.AddDate(0, 0, day), []string{"zfs"}, results)
+		trends.PredictFull(c, []string{"zfs"}, results)
+		return results["zfs"]
+	}
+	run(0, 50)
+	if sr := run(1, 52); sr.Items[0].Value != "52% used" {
+		t.Errorf("predicted with two samples: %q", sr.Items[0].Value)
+	}
+	sr := run(2, 54)
+	if expected := "54% used, full in ~23d"; sr.Items[0].Value != expected {
+		t.Errorf("got %q, expected %q", sr.Items[0].Value, expected)
+	}
+	if m := sr.Items[0].Metrics[1]; m.Name != "days_until_full" || m.Value != 23 {
+		t.Errorf("got metric %+v", m)
+	}
+	if sr.Status != StatusOK {
+		t.Errorf("got status %s with three samples, expected ok", sr.Status)
+	}
+	if sr := run(3, 48); sr.Items[0].Value != "48% used" {
+		t.Errorf("predicted with usage decreasing: %q", sr.Items[0].Value)
+	}
+	trends = NewTrends(10)
+	for day := 0; day < minStatusSamples; day++ {
+		sr = run(day, float64(50+2*day))
+	}
+	if expected := "68% used, full in ~16d"; sr.Items[0].Value != expected {
+		t.Errorf("got %q, expected %q", sr.Items[0].Value, expected)
+	}
+	if sr.Status != StatusWarning || sr.Items[0].Status != StatusWarning {
+		t.Errorf("got status %s, expected warning", sr.Status)
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Now()
+	samples := []Sample{{now, 80}, {now.Add(time.Hour), 85}, {now.Add(2 * time.Hour), 90}}
+	days, ok := DaysUntil(samples, 95)
+	if !ok || formatDays(days) != "~1h" {
+		t.Errorf("got %v %s, expected ~1h", ok, formatDays(days))
+	}
+	if _, ok := DaysUntil(samples, 90); ok {
+		t.Errorf("predicted a target which was reached")
+	}
+}
+
+func TestTrendsUnmarshalOldFormat(t *testing.T) {
+	trends := NewTrends(5)
+	if err := json.Unmarshal([]byte(`{"zfs/tank":[50,51,52]}`), trends); err != nil {
+		t.Fatal(err)
+	}
+	samples := trends.Samples("zfs", "tank")
+	if len(samples) != 3 || samples[2].Value != 52 || !samples[2].Time.IsZero() {
+		t.Fatalf("got %v", samples)
+	}
+	if _, ok := DaysUntil(samples, 100); ok {
+		t.Errorf("predicted from samples without a time")
+	}
+}
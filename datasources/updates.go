@@ -31,35 +31,51 @@ type ConfUpdates struct {
 	ShortNames bool `yaml:"short_names"`
 }
 
-// Init sets default alignment and default socket file
+// Init sets the default socket file
 func (c *ConfUpdates) Init() {
 	c.ConfBase.Init()
-	c.PadHeader = []int{0, 2}
-	c.PadContent = []int{1, 0}
 	c.Address = "/run/go-check-updates.sock"
 	c.Every = "1h"
 }
 
+// Validate checks ConfBase and that every is a valid duration, it is empty to disable refreshing
+// and not used when reading the cache file directly
+func (c *ConfUpdates) Validate() []FieldError {
+	errs := c.ConfBase.Validate()
+	if c.Every == "" || c.File != "" {
+		return errs
+	}
+	if _, err := time.ParseDuration(c.Every); err != nil {
+		errs = append(errs, FieldError{"every", fmt.Sprintf("invalid duration %q", c.Every)})
+	}
+	return errs
+}
+
+func init() {
+	Register(NewDatasource("updates", "Updates", func() ConfInterface { return &ConfUpdates{} }, GetUpdates))
+}
+
 // GetUpdates reads cached updates file and formats it
-func GetUpdates(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.Updates
+func GetUpdates(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["updates"].(*ConfUpdates)
 	// Check for warnOnly override
 	if c.Show == nil {
 		c.Show = &conf.WarnOnly
 	}
-	sr := NewSourceReturn(conf.debug)
+	sr := NewSourceReturn("Updates")
+	sr.Status = StatusInfo
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
 	if c.File != "" {
-		sr.Header, sr.Content, sr.Error = getUpdatesFile(&c)
+		sr.Error = getUpdatesFile(&c, sr)
 	} else {
-		sr.Header, sr.Content, sr.Error = getUpdatesAPI(&c)
+		sr.Error = getUpdatesAPI(ctx, &c, sr)
 	}
 }
 
 // getUpdatesResponse connects to go-check-updates at addr and returns the result
-func getUpdatesResponse(addr string, url string, padL string, padR string) (header string, result api.Response, err error) {
+func getUpdatesResponse(ctx context.Context, addr string, url string, sr *SourceReturn) (result api.Response, err error) {
 	var client http.Client
 	var connType string
 	log.Debugf("[updates] request URL: %s", url)
@@ -73,73 +89,83 @@ func getUpdatesResponse(addr string, url string, padL string, padR string) (head
 	client = http.Client{
 		Timeout: 1 * time.Second,
 		Transport: &http.Transport{
-			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
-				return net.Dial(connType, addr)
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, connType, addr)
 			},
 		},
 	}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		err = &ModuleNotAvailable{"updates", err}
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Updates", padL, padR), utils.Warn("unavailable"))
+		sr.Status = StatusUnavailable
 		return
 	}
 	defer resp.Body.Close()
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		err = &ModuleNotAvailable{"updates", err}
-		header = fmt.Sprintf("%s: %s (%v)\n", utils.Wrap("Updates", padL, padR), utils.Warn("Cannot decode response"), err)
+		sr.Status = StatusUnavailable
+		sr.Message = fmt.Sprintf("Cannot decode response (%v)", err)
 		return
 	}
 	log.Debugf("[updates] response:\n%s", utils.PrettyPrint(&result))
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		err = &ModuleNotAvailable{"updates", fmt.Errorf("invalid response code %s", resp.Status)}
-		header = fmt.Sprintf("%s: %s (%s)\n", utils.Wrap("Updates", padL, padR), utils.Warn("Invalid response"), resp.Status)
+		sr.Status = StatusUnavailable
+		sr.Message = fmt.Sprintf("Invalid response (%s)", resp.Status)
 		return
 	}
 	return
 }
 
 // getUpdatesAPI gets currently cached updates and queues an update if needed
-func getUpdatesAPI(c *ConfUpdates) (header string, content string, err error) {
+func getUpdatesAPI(ctx context.Context, c *ConfUpdates, sr *SourceReturn) (err error) {
 	var r api.Response
 	reqURL := "http://con/api?updates"
 	if c.Every != "" {
 		reqURL += fmt.Sprintf("&refresh&immediate&every=%s", c.Every)
 	}
-	header, r, err = getUpdatesResponse(c.Address, reqURL, c.padL, c.padR)
+	r, err = getUpdatesResponse(ctx, c.Address, reqURL, sr)
 	if err != nil {
 		return
 	}
 	if r.Error != "" {
 		log.Warnf("[updates] response contains error %s", r.Error)
-		content = fmt.Sprintf("%s\n", utils.Warn(r.Error))
+		sr.AddItem("", r.Error, StatusWarning)
 	}
 	if r.Queued != nil && *r.Queued {
 		if r.Data == nil {
-			header = fmt.Sprintf("%s: No data, refreshing\n", utils.Wrap("Updates", c.padL, c.padR))
+			sr.Message = "No data, refreshing"
 		} else {
-			header = fmt.Sprintf("%s: %d pending, refreshing\n", utils.Wrap("Updates", c.padL, c.padR), len(r.Data.Updates))
+			sr.Message = fmt.Sprintf("%d pending, refreshing", len(r.Data.Updates))
 		}
 	} else {
 		if r.Data == nil {
-			header = fmt.Sprintf("%s: No data\n", utils.Wrap("Updates", c.padL, c.padR))
+			sr.Message = "No data"
 			return
 		}
 		t, err := time.Parse(time.RFC3339, r.Data.Checked)
 		if err != nil {
 			log.Warnf("[updates] cannot parse timestamp %s: %v", r.Data.Checked, err)
-			header = fmt.Sprintf("%s: %d pending, cannot parse timestamp\n", utils.Wrap("Updates", c.padL, c.padR), len(r.Data.Updates))
-			return header, content, err
+			sr.Status = StatusUnknown
+			sr.Message = fmt.Sprintf("%d pending, cannot parse timestamp", len(r.Data.Updates))
+			return err
 		}
 		var timeElapsed = time.Since(t)
-		header = fmt.Sprintf("%s: %d pending, checked %s ago\n",
-			utils.Wrap("Updates", c.padL, c.padR), len(r.Data.Updates), timeStr(timeElapsed, 2, c.ShortNames))
+		sr.Message = fmt.Sprintf("%d pending, checked %s ago", len(r.Data.Updates), timeStr(timeElapsed, 2, c.ShortNames))
+	}
+	if r.Data != nil {
+		sr.Metrics = []Metric{{Name: "pending", Value: float64(len(r.Data.Updates))}}
 	}
 	if r.Data == nil || c.Show == nil || !*c.Show {
 		return
 	}
-	content += fmt.Sprint(utils.Wrap(r.Data.String(), c.padL, c.padR))
+	sr.AddItem("", r.Data.String(), StatusInfo)
 	return
 }
 
@@ -153,20 +179,20 @@ func readUpdatesCache(cacheFp string) (parsed api.File, err error) {
 	return
 }
 
-func getUpdatesFile(c *ConfUpdates) (header string, content string, err error) {
+func getUpdatesFile(c *ConfUpdates, sr *SourceReturn) (err error) {
 	data, err := readUpdatesCache(c.File)
 	if err != nil {
 		err = &ModuleNotAvailable{"updates", err}
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("Updates", c.padL, c.padR), utils.Warn("unavailable"))
+		sr.Status = StatusUnavailable
 		return
 	}
 	t, _ := time.Parse(time.RFC3339, data.Checked)
 	var timeElapsed = time.Since(t)
-	header = fmt.Sprintf("%s: %d pending, checked %s ago\n",
-		utils.Wrap("Updates", c.padL, c.padR), len(data.Updates), timeStr(timeElapsed, 2, c.ShortNames))
+	sr.Message = fmt.Sprintf("%d pending, checked %s ago", len(data.Updates), timeStr(timeElapsed, 2, c.ShortNames))
+	sr.Metrics = []Metric{{Name: "pending", Value: float64(len(data.Updates))}}
 	if c.Show == nil || !*c.Show {
 		return
 	}
-	content += fmt.Sprint(utils.Wrap(data.String(), c.padL, c.padR))
+	sr.AddItem("", data.String(), StatusInfo)
 	return
 }
@@ -0,0 +1,241 @@
+package datasources
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConfigError is a problem found in a config file
+type ConfigError struct {
+	// File the problem was found in, empty if unknown
+	File string
+	// Line in the config file, 0 if unknown
+	Line int
+	// Section of the config file, global or a datasource name
+	Section string
+	// Key in the section, empty if the problem is with the section itself
+	Key string
+	Msg string
+}
+
+func (e ConfigError) Error() string {
+	var sb strings.Builder
+	if e.File != "" && e.Line > 0 {
+		_, _ = fmt.Fprintf(&sb, "%s:%d: ", e.File, e.Line)
+	} else if e.File != "" {
+		sb.WriteString(e.File + ": ")
+	} else if e.Line > 0 {
+		_, _ = fmt.Fprintf(&sb, "line %d: ", e.Line)
+	}
+	if e.Section != "" {
+		sb.WriteString(e.Section)
+		if e.Key != "" {
+			sb.WriteString("." + e.Key)
+		}
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Msg)
+	return sb.String()
+}
+
+// ConfigErrors are all problems found in a config file
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, ce := range e {
+		lines[i] = ce.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FieldError is a problem with the value of a key in a module config
+type FieldError struct {
+	Key string
+	Msg string
+}
+
+// Validator is implemented by module configs which can check their values, it is implemented
+// by ConfBase so custom modules only need to override it to add their own checks.
+type Validator interface {
+	Validate() []FieldError
+}
+
+// Validate checks the padding arrays, timeout, bar and sparkline width
+func (c *ConfBase) Validate() (errs []FieldError) {
+	if len(c.PadHeader) != 0 && len(c.PadHeader) != 2 {
+		errs = append(errs, FieldError{"pad_header", fmt.Sprintf("must have 2 elements, got %d", len(c.PadHeader))})
+	}
+	if len(c.PadContent) != 0 && len(c.PadContent) != 2 {
+		errs = append(errs, FieldError{"pad_content", fmt.Sprintf("must have 2 elements, got %d", len(c.PadContent))})
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, FieldError{"timeout", "cannot be negative"})
+	}
+	if c.BarWidth < 0 {
+		errs = append(errs, FieldError{"bar_width", "cannot be negative"})
+	}
+	if c.SparklineWidth < 0 {
+		errs = append(errs, FieldError{"sparkline_width", "cannot be negative"})
+	}
+	return
+}
+
+// Validate checks ConfBase and that warn is less than crit
+func (c *ConfBaseWarn) Validate() []FieldError {
+	errs := c.ConfBase.Validate()
+	if c.Warn >= c.Crit {
+		errs = append(errs, FieldError{"warn", fmt.Sprintf("must be less than crit (%d), got %d", c.Crit, c.Warn)})
+	}
+	return errs
+}
+
+// validate returns all problems with the decoded config, without line numbers
+func (c *Conf) validate() (errs ConfigErrors) {
+	checkNames := func(key string, names []string) {
+		for _, k := range names {
+			if _, ok := Lookup(k); !ok {
+				errs = append(errs, ConfigError{Section: globalKey, Key: key, Msg: "no data source named " + k})
+			}
+		}
+	}
+	checkNames("show_order", c.ShowOrder)
+	for _, row := range c.ColDef {
+		checkNames("col_def", ColumnNames(row))
+		for _, col := range row {
+			if err := col.Validate(); err != nil {
+				errs = append(errs, ConfigError{Section: globalKey, Key: "col_def", Msg: err.Error()})
+			}
+		}
+	}
+	if c.ColPad < 0 {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "col_pad", Msg: "cannot be negative"})
+	}
+	if c.MaxWidth < 0 {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "max_width", Msg: "cannot be negative"})
+	}
+	if c.TrendSamples < 0 {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "trend_samples", Msg: "cannot be negative"})
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "timeout", Msg: "cannot be negative"})
+	}
+	if c.HookTimeout < 0 {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "hook_timeout", Msg: "cannot be negative"})
+	}
+	if err := c.validateTemplate(); err != nil {
+		errs = append(errs, ConfigError{Section: globalKey, Key: "template", Msg: err.Error()})
+	}
+	for _, o := range c.Outputs {
+		if err := o.Validate(); err != nil {
+			errs = append(errs, ConfigError{Section: globalKey, Key: "outputs", Msg: err.Error()})
+		}
+	}
+	for _, w := range c.Webhooks {
+		if err := w.Validate(); err != nil {
+			errs = append(errs, ConfigError{Section: globalKey, Key: "webhooks", Msg: err.Error()})
+		}
+	}
+	for _, fe := range c.Theme.Validate() {
+		errs = append(errs, ConfigError{Section: themeKey, Key: fe.Key, Msg: fe.Msg})
+	}
+	for _, k := range Names() {
+		mc, ok := c.Modules[k]
+		if !ok {
+			continue
+		}
+		var fieldErrs []FieldError
+		if v, ok := mc.(Validator); ok {
+			fieldErrs = v.Validate()
+		} else {
+			fieldErrs = mc.Base().Validate()
+		}
+		if f, ok := mc.(filler); ok {
+			fieldErrs = append(fieldErrs, f.fill().validateFill()...)
+		}
+		for _, fe := range fieldErrs {
+			errs = append(errs, ConfigError{Section: k, Key: fe.Key, Msg: fe.Msg})
+		}
+	}
+	return
+}
+
+// Validate returns ConfigErrors if the config cannot be used to run modules
+func (c *Conf) Validate() error {
+	if errs := c.validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var (
+	reLineError    = regexp.MustCompile(`^line (\d+): (.*)$`)
+	reUnknownField = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// parseTypeError converts an error message of yaml.TypeError to a ConfigError
+func parseTypeError(data []byte, s string) ConfigError {
+	m := reLineError.FindStringSubmatch(s)
+	if m == nil {
+		return ConfigError{Msg: s}
+	}
+	ce := ConfigError{Msg: m[2]}
+	ce.Line, _ = strconv.Atoi(m[1])
+	ce.Section = sectionAt(data, ce.Line)
+	if f := reUnknownField.FindStringSubmatch(m[2]); f != nil {
+		ce.Key = f[1]
+		ce.Msg = "unknown key"
+	}
+	return ce
+}
+
+// keyLine returns the line of key in a top-level section of a YAML document, the line of the
+// section is returned if key is empty or not found, 0 if the section is not found.
+func keyLine(data []byte, section string, key string) int {
+	sectionLine := 0
+	indent := -1
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(trimmed)
+		if depth == 0 {
+			if sectionLine > 0 {
+				break
+			}
+			if strings.HasPrefix(line, section+":") {
+				sectionLine = i + 1
+				if key == "" {
+					break
+				}
+			}
+			continue
+		}
+		if sectionLine == 0 {
+			continue
+		}
+		if indent < 0 {
+			indent = depth
+		}
+		if depth == indent && strings.HasPrefix(trimmed, key+":") {
+			return i + 1
+		}
+	}
+	return sectionLine
+}
+
+// sectionAt returns the top-level section which contains line of a YAML document
+func sectionAt(data []byte, line int) (section string) {
+	for i, l := range strings.Split(string(data), "\n") {
+		if i >= line {
+			break
+		}
+		if l != "" && l[0] != ' ' && l[0] != '#' && l[0] != '-' {
+			section, _, _ = strings.Cut(l, ":")
+		}
+	}
+	return
+}
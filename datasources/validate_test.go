@@ -0,0 +1,165 @@
+package datasources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes files relative to a temporary directory and returns the path of the first one
+func writeConfig(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, confDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+func TestCheckConfig(t *testing.T) {
+	path := writeConfig(t, map[string]string{"config.yaml": `global:
+  warning_only: true
+  show_order: [test_module, missing]
+test_module:
+  pad_headr: [0, 2]
+  pad_header: [0]
+  value: 2
+cpu:
+  warn: 90
+  crit: 70
+nope:
+  value: 1
+`})
+	_, err := CheckConfig(path, "")
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	expected := []string{
+		"config.yaml:2: global.warning_only: unknown key",
+		"config.yaml:3: global.show_order: no data source named missing",
+		"config.yaml:5: test_module.pad_headr: unknown key",
+		"config.yaml:6: test_module.pad_header: must have 2 elements, got 1",
+		"config.yaml:9: cpu.warn: must be less than crit (70), got 90",
+		"config.yaml:11: nope: no data source with this name",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("got %d errors, expected %d:\n%v", len(errs), len(expected), errs)
+	}
+	for i, e := range errs {
+		e.File = filepath.Base(e.File)
+		if e.Error() != expected[i] {
+			t.Errorf("got %q, expected %q", e.Error(), expected[i])
+		}
+	}
+}
+
+func TestInvalidValuesKeepConfig(t *testing.T) {
+	path := writeConfig(t, map[string]string{"config.yaml": "global:\n  warnings_only: false\n  show_order: [zfs, missing]\nzfs:\n  timeout: 5s\n"})
+	c, err := NewConfFromFile(path, false)
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected an error about missing, got %v", err)
+	}
+	if c.WarnOnly || len(c.ShowOrder) != 2 || c.Modules["zfs"].Base().Timeout != 5*time.Second {
+		t.Errorf("decoded config was not kept: %+v", c.ConfGlobal)
+	}
+	path = writeConfig(t, map[string]string{"config.yaml": "global:\n  warnings_only: false\n show_order: [zfs\n"})
+	if c, err = NewConfFromFile(path, false); err == nil || !c.WarnOnly {
+		t.Errorf("expected defaults for a config which cannot be parsed, got %v", err)
+	}
+}
+
+func TestConfDir(t *testing.T) {
+	path := writeConfig(t, map[string]string{
+		"config.yaml":           "global:\n  col_pad: 2\nzfs:\n  warn: 50\n  crit: 60\n",
+		"conf.d/10-nas.yaml":    "zfs:\n  crit: 95\n",
+		"conf.d/20-docker.yaml": "docker:\n  ignore: [a]\n",
+		"conf.d/30-bad.yaml":    "zfs:\n  warn: 99\n",
+		"conf.d/ignored.yml":    "global:\n  col_pad: 3\n",
+	})
+	_, err := CheckConfig(path, "")
+	errs, ok := err.(ConfigErrors)
+	if !ok || len(errs) != 1 || filepath.Base(errs[0].File) != "30-bad.yaml" || errs[0].Line != 2 {
+		t.Fatalf("expected error in 30-bad.yaml line 2, got %v", err)
+	}
+	if err := os.Remove(filepath.Join(filepath.Dir(path), confDir, "30-bad.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConfFromFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zfs := c.Modules["zfs"].(*ConfZFS)
+	if c.ColPad != 2 || zfs.Warn != 50 || zfs.Crit != 95 {
+		t.Errorf("got col_pad %d, zfs warn %d crit %d, expected 2, 50, 95", c.ColPad, zfs.Warn, zfs.Crit)
+	}
+	if o := filepath.Base(c.Origin("zfs", "crit")); o != "10-nas.yaml" {
+		t.Errorf("zfs.crit origin: got %s", o)
+	}
+	out, err := c.MarshalWithOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "  ignore: # "+filepath.Join(filepath.Dir(path), confDir, "20-docker.yaml")) {
+		t.Errorf("missing origin of docker.ignore:\n%s", out)
+	}
+}
+
+func TestUserOverlay(t *testing.T) {
+	path := writeConfig(t, map[string]string{
+		"config.yaml": "global:\n  warnings_only: true\nzfs:\n  warn: 50\n  crit: 60\n",
+		"user.yaml":   "global:\n  warnings_only: false\n  show_order: [zfs]\nzfs:\n  crit: 99\n  pad_header: [1, 1]\n  warn_days: 300\n",
+	})
+	overlay := filepath.Join(filepath.Dir(path), "user.yaml")
+	c, err := NewConfWithOverlay(path, overlay, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zfs := c.Modules["zfs"].(*ConfZFS)
+	if c.WarnOnly || len(c.ShowOrder) != 1 || zfs.PadHeader[0] != 1 {
+		t.Errorf("overlay not applied: %+v", c.ConfGlobal)
+	}
+	if zfs.Crit != 60 || c.Origin("zfs", "crit") != path {
+		t.Errorf("zfs crit: got %d from %s, expected 60 from system config", zfs.Crit, c.Origin("zfs", "crit"))
+	}
+	if zfs.WarnDays != 0 {
+		t.Errorf("zfs warn_days: got %d, expected the default from system config", zfs.WarnDays)
+	}
+	_, err = CheckConfig(path, overlay)
+	if errs, ok := err.(ConfigErrors); !ok || len(errs) != 2 || errs[0].Line != 5 || errs[0].Key != "crit" || errs[1].Key != "warn_days" {
+		t.Errorf("expected crit error on line 5 and warn_days error, got %v", err)
+	}
+	// A missing overlay is skipped
+	c, err = NewConfWithOverlay(path, overlay+".missing", false)
+	if err != nil || !c.WarnOnly || c.Modules["zfs"].(*ConfZFS).Warn != 50 {
+		t.Errorf("system config not kept without overlay: %v", err)
+	}
+	if _, err = CheckConfig(path, overlay+".missing"); err == nil {
+		t.Errorf("expected an error for a missing overlay in strict mode")
+	}
+}
+
+func TestUpdatesEvery(t *testing.T) {
+	for _, tc := range []struct {
+		file, every string
+		errs        int
+	}{
+		{"", "1h", 0},
+		{"", "", 0},
+		{"", "daily", 1},
+		{"/var/cache/go-check-updates/cache.json", "daily", 0},
+	} {
+		var c ConfUpdates
+		c.Init()
+		c.File, c.Every = tc.file, tc.every
+		if errs := c.Validate(); len(errs) != tc.errs {
+			t.Errorf("file %q every %q: got %v, expected %d errors", tc.file, tc.every, errs, tc.errs)
+		}
+	}
+}
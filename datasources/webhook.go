@@ -0,0 +1,83 @@
+package datasources
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Webhook defaults
+const (
+	defaultWebhookTimeout  = 10 * time.Second
+	defaultWebhookRetries  = 3
+	defaultWebhookBackoff  = time.Second
+	defaultWebhookCooldown = 5 * time.Minute
+)
+
+// Webhook is a URL status changes are posted to in daemon mode
+type Webhook struct {
+	// URL the JSON payload is posted to, http or https
+	URL string `yaml:"url"`
+	// Extra request headers, for example Authorization
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Maximum time a request may take, 10s by default
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Number of retries after a failed request, 3 by default
+	Retries *int `yaml:"retries,omitempty"`
+	// Time to wait before the first retry, doubled after every retry, 1s by default
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+	// Minimum time between notifications about the same module or item, 5m by default
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+}
+
+// RequestTimeout returns the timeout of a request, 10s if not set
+func (w *Webhook) RequestTimeout() time.Duration {
+	if w.Timeout == 0 {
+		return defaultWebhookTimeout
+	}
+	return w.Timeout
+}
+
+// MaxRetries returns the number of retries after a failed request, 3 if not set
+func (w *Webhook) MaxRetries() int {
+	if w.Retries == nil {
+		return defaultWebhookRetries
+	}
+	return *w.Retries
+}
+
+// RetryBackoff returns the time to wait before the first retry, 1s if not set
+func (w *Webhook) RetryBackoff() time.Duration {
+	if w.Backoff == 0 {
+		return defaultWebhookBackoff
+	}
+	return w.Backoff
+}
+
+// NotifyCooldown returns the minimum time between notifications about the same module or item, 5m if not set
+func (w *Webhook) NotifyCooldown() time.Duration {
+	if w.Cooldown == 0 {
+		return defaultWebhookCooldown
+	}
+	return w.Cooldown
+}
+
+// Validate checks the URL and that durations and retries are not negative
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return err
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: must be an http or https URL", w.URL)
+	}
+	if w.Timeout < 0 || w.Backoff < 0 || w.Cooldown < 0 {
+		return fmt.Errorf("%s: timeout, backoff and cooldown cannot be negative", w.URL)
+	}
+	if w.Retries != nil && *w.Retries < 0 {
+		return fmt.Errorf("%s: retries cannot be negative", w.URL)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -12,12 +13,11 @@ import (
 
 type ConfZFS struct {
 	ConfBaseWarn `yaml:",inline"`
+	ConfFill     `yaml:",inline"`
 }
 
-// Init sets up default alignment
-func (c *ConfZFS) Init() {
-	c.ConfBaseWarn.Init()
-	c.PadHeader[1] = 6
+func init() {
+	Register(NewDatasource("zfs", "ZFS", func() ConfInterface { return &ConfZFS{} }, GetZFS))
 }
 
 // zpool list -Hpo name,alloc,size,health
@@ -25,61 +25,44 @@ func (c *ConfZFS) Init() {
 // Sizes are in bytes
 
 // GetZFS runs `zpool list -Ho name,alloc,size,health` and parses the output
-func GetZFS(ch chan<- SourceReturn, conf *Conf) {
-	c := conf.ZFS
-	// Check for *c.WarnOnly override
-	if c.WarnOnly == nil {
-		c.WarnOnly = &conf.WarnOnly
-	}
-	sr := NewSourceReturn(conf.debug)
+func GetZFS(ctx context.Context, ch chan<- SourceReturn, conf *Conf) {
+	c := *conf.Modules["zfs"].(*ConfZFS)
+	sr := NewSourceReturn("ZFS")
 	defer func() {
-		ch <- sr.Return(&c.ConfBase)
+		ch <- sr.Return()
 	}()
-	sr.Header, sr.Content, sr.Error = getPoolStatus(&c)
+	sr.Error = getPoolStatus(ctx, &c, sr)
 }
 
-func getPoolStatus(c *ConfZFS) (header string, content string, err error) {
+func getPoolStatus(ctx context.Context, c *ConfZFS, sr *SourceReturn) (err error) {
 	var buf bytes.Buffer
-	cmd := exec.Command("zpool", "list", "-Hpo", "name,alloc,size,health")
+	cmd := exec.CommandContext(ctx, "zpool", "list", "-Hpo", "name,alloc,size,health")
 	cmd.Stdout = &buf
 	err = cmd.Run()
 	if err != nil {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("ZFS", c.padL, c.padR), utils.Warn("unavailable"))
+		sr.Status = StatusUnavailable
 		err = &ModuleNotAvailable{"zfs", err}
 		return
 	}
-	var status = 'o'
 	for _, pool := range strings.Split(buf.String(), "\n") {
 		var tmp = strings.Split(pool, "\t")
-		if len(tmp) < 3 {
+		if len(tmp) < 4 {
 			continue
 		}
 		usedBytes, _ := strconv.ParseFloat(tmp[1], 64)
 		totalBytes, _ := strconv.ParseFloat(tmp[2], 64)
-		var usedStr = utils.FormatBytes(usedBytes)
-		var totalStr = utils.FormatBytes(totalBytes)
 		usedPerc := int((usedBytes / totalBytes) * 100)
+		item := Item{
+			Name:    tmp[0],
+			Value:   fmt.Sprintf("%s, %s used out of %s", tmp[3], utils.FormatBytes(usedBytes), utils.FormatBytes(totalBytes)),
+			Status:  c.Status(usedPerc),
+			Metrics: usageMetrics(&c.ConfBaseWarn, usedBytes, totalBytes),
+		}
 		if tmp[3] != "ONLINE" {
-			status = 'e'
-			content += fmt.Sprintf("%s: %s, %s used out of %s\n", utils.Wrap(tmp[0], c.padL, c.padR), utils.Err(tmp[3]), usedStr, totalStr)
-		} else if usedPerc < c.Warn && !*c.WarnOnly {
-			content += fmt.Sprintf("%s: %s, %s used out of %s\n", utils.Wrap(tmp[0], c.padL, c.padR), utils.Good(tmp[3]), usedStr, totalStr)
-		} else if usedPerc >= c.Warn && usedPerc < c.Crit {
-			if status != 'e' {
-				status = 'w'
-			}
-			content += fmt.Sprintf("%s: %s, %s used out of %s\n", utils.Wrap(tmp[0], c.padL, c.padR), utils.Warn(tmp[3]), usedStr, totalStr)
-		} else if usedPerc >= c.Crit {
-			status = 'e'
-			content += fmt.Sprintf("%s: %s, %s used out of %s\n", utils.Wrap(tmp[0], c.padL, c.padR), utils.Err(tmp[3]), usedStr, totalStr)
+			item.Status = StatusCritical
 		}
+		sr.Items = append(sr.Items, item)
 	}
-	if status == 'o' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("ZFS", c.padL, c.padR), utils.Good("OK"))
-	} else if status == 'w' {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("ZFS", c.padL, c.padR), utils.Warn("Warning"))
-	} else {
-		header = fmt.Sprintf("%s: %s\n", utils.Wrap("ZFS", c.padL, c.padR), utils.Err("Critical"))
-	}
+	sr.Status = WorstStatus(StatusOK, sr.Items)
 	return
 }
@@ -1,22 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/alexflint/go-arg"
-	"github.com/olekukonko/tablewriter"
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/writer"
 	"gopkg.in/yaml.v2"
 
 	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/notify"
+	"github.com/cosandr/go-motd/render"
 	"github.com/cosandr/go-motd/utils"
 )
 
@@ -25,51 +28,6 @@ const defaultRefresh string = "10m"
 var defaultCfgPath = "./config.yaml"
 var defaultOrder = []string{"sysinfo", "updates", "systemd", "docker", "podman", "disk", "cpu", "zfs", "btrfs"}
 
-func makeTable(buf *strings.Builder, padding int) (table *tablewriter.Table) {
-	table = tablewriter.NewWriter(buf)
-	table.SetAutoWrapText(false)
-	table.SetAutoFormatHeaders(false)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.SetHeaderLine(false)
-	table.SetBorder(false)
-	table.SetTablePadding(strings.Repeat(" ", padding))
-	table.SetNoWhiteSpace(true)
-	return
-}
-
-func mapToTable(buf *strings.Builder, inStr map[string]string, colDef [][]string, padding int) {
-	table := makeTable(buf, padding)
-	var tmp []string
-	// Render a new table every row for compact output
-	for _, row := range colDef {
-		// Just write block to buffer if it is alone
-		if len(row) == 1 {
-			a, ok := inStr[row[0]]
-			// Skip invalid modules
-			if !ok {
-				continue
-			}
-			_, _ = fmt.Fprintln(buf, a)
-			continue
-		}
-		tmp = nil
-		for _, k := range row {
-			a, ok := inStr[k]
-			if !ok {
-				continue
-			}
-			tmp = append(tmp, a)
-		}
-		table.Append(tmp)
-		table.Render()
-		// Remake table to avoid imbalanced output
-		table = makeTable(buf, padding)
-	}
-}
-
 // makePrintOrder flattens colDef (if present). If showOrder is defined as well, it is ignored.
 func makePrintOrder(c *datasources.Conf) (printOrder []string) {
 	if args.Updates {
@@ -78,9 +36,7 @@ func makePrintOrder(c *datasources.Conf) (printOrder []string) {
 	if len(c.ColDef) > 0 {
 		// Flatten 2-dim input
 		for _, row := range c.ColDef {
-			for _, k := range row {
-				printOrder = append(printOrder, k)
-			}
+			printOrder = append(printOrder, datasources.ColumnNames(row)...)
 		}
 	} else if len(c.ShowOrder) > 0 {
 		printOrder = c.ShowOrder
@@ -93,18 +49,25 @@ func makePrintOrder(c *datasources.Conf) (printOrder []string) {
 }
 
 var args struct {
-	ConfigFile      string        `arg:"-c,--config,env:CONFIG_FILE" help:"Path to config yaml"`
+	Check           bool          `arg:"--check" help:"Print a Nagios plugin compatible summary and exit with its status code"`
+	CheckConfig     bool          `arg:"--check-config" help:"Strictly validate the config file, report all problems and exit"`
+	Color           string        `arg:"--color,env:COLOR" help:"Use colors, auto, always or never; auto only colors terminals and honors NO_COLOR and TERM=dumb"`
+	ConfigFile      string        `arg:"-c,--config" help:"Path to system config yaml"`
 	Daemon          bool          `arg:"-d,--daemon,env:DAEMON" help:"Run in daemon mode"`
 	Debug           bool          `arg:"--debug,env:DEBUG" help:"Debug mode"`
 	DumpConfig      bool          `arg:"--dump-config" help:"Dump config and exit"`
+	Format          string        `arg:"-f,--format,env:FORMAT" help:"Output format, text, json, html, waybar or tmux"`
 	HideUnavailable bool          `arg:"--hide-unavailable,env:HIDE_UNAVAILABLE" help:"Hide unavailable modules"`
 	LogLevel        string        `arg:"--log-level,env:LOG_LEVEL" help:"Set log level"`
-	NoColors        bool          `arg:"--no-colors,env:NO_COLORS" help:"Disable colors"`
-	Output          string        `arg:"-o,--output,env:OUTPUT" help:"Write output to file instead of stdout"`
+	MetricsListen   string        `arg:"--metrics-listen,env:METRICS_LISTEN" help:"Serve Prometheus metrics on this address or unix socket in daemon mode"`
+	NoColors        bool          `arg:"--no-colors,env:NO_COLORS" help:"Disable colors, same as --color=never"`
+	Output          string        `arg:"-o,--output,env:OUTPUT" help:"Write output to file instead of stdout, replaces outputs in the config file"`
 	PID             string        `arg:"--pid" help:"Write PID to file or log if '-'"`
 	Quiet           bool          `arg:"-q,--quiet" help:"Don't log to console"`
 	RefreshInterval time.Duration `arg:"--refresh-interval,env:REFRESH_INTERVAL" help:"Time interval between data refreshes"`
+	ShowOrigin      bool          `arg:"--show-origin" help:"Annotate --dump-config output with the file each value was set in"`
 	Updates         bool          `arg:"-u,--updates" help:"Show pending updates and exit"`
+	UserConfig      string        `arg:"--user-config,env:CONFIG_FILE" help:"Path to per-user config yaml merged over the system config, defaults to $XDG_CONFIG_HOME/go-motd/config.yaml"`
 }
 
 func setupLogging() {
@@ -149,11 +112,32 @@ func setupLogging() {
 	}
 }
 
-func runModules(c *datasources.Conf) {
-	outOrder, outData := datasources.RunSources(makePrintOrder(c), c)
-	outStr := make(map[string]string)
-	// Wait and save results
+// renderText renders modules as text, arranged in columns if col_def is set and no wider than width if it is not 0
+func renderText(c *datasources.Conf, width int, outOrder []string, outData map[string]datasources.SourceReturn) string {
+	blocks := make(map[string]*render.Block)
 	for _, k := range outOrder {
+		v := outData[k]
+		blocks[k] = render.TextBlock(&v, c.ModuleBase(k), c.ModuleWarnOnly(k))
+	}
+	if len(c.ColDef) > 0 {
+		log.Debug("Format as table")
+		colDef := c.ColDef
+		// Changes are shown first
+		if _, ok := blocks[datasources.ChangesKey]; ok {
+			colDef = append([][]datasources.Column{{{Name: datasources.ChangesKey}}}, colDef...)
+		}
+		return render.Layout(outOrder, blocks, colDef, c.ColPad, width)
+	}
+	log.Debug("Print as is")
+	return render.Layout(outOrder, blocks, nil, c.ColPad, width)
+}
+
+// collect runs all modules and returns the ones which should be shown
+func collect(c *datasources.Conf) ([]string, map[string]datasources.SourceReturn) {
+	runOrder, outData := datasources.RunSources(context.Background(), makePrintOrder(c), c)
+	var outOrder []string
+	// Wait and save results
+	for _, k := range runOrder {
 		v, ok := outData[k]
 		if !ok {
 			continue
@@ -165,35 +149,95 @@ func runModules(c *datasources.Conf) {
 		if v.Error != nil {
 			log.Warnf("%s error: %v", k, v.Error)
 		}
-		outStr[k] = v.Header
-		if v.Content != "" {
-			outStr[k] += "\n" + v.Content
-		}
-	}
-	outBuf := &strings.Builder{}
-	if len(c.ColDef) > 0 {
-		log.Debug("Format as table")
-		mapToTable(outBuf, outStr, c.ColDef, c.ColPad)
-	} else {
-		log.Debug("Print as is")
-		for _, k := range outOrder {
-			_, _ = fmt.Fprintln(outBuf, outStr[k])
-		}
-	}
-	if args.Output != "" {
-		err := os.WriteFile(args.Output, []byte(outBuf.String()), 0644)
-		if err != nil {
-			log.Error(err)
-		}
-	} else {
-		fmt.Print(outBuf.String())
+		outOrder = append(outOrder, k)
 	}
 	// Show timing results
 	if args.Debug {
-		for _, k := range outOrder {
+		for _, k := range runOrder {
 			log.Debugf("%s ran in: %s", k, outData[k].Time.String())
 		}
 	}
+	return outOrder, outData
+}
+
+// runCheck runs all modules once, prints a Nagios plugin compatible summary and returns the exit code
+func runCheck(c *datasources.Conf) int {
+	line, code := render.Check(collect(c))
+	fmt.Println(line)
+	return code
+}
+
+// runModules runs all modules, writes their output and returns the results with the changes since the previous run
+// if history is enabled, samples are added to trends unless it is nil
+func runModules(c *datasources.Conf, trends *datasources.Trends) ([]string, map[string]datasources.SourceReturn, *datasources.SourceReturn) {
+	outOrder, outData := collect(c)
+	if trends != nil {
+		trends.Record(time.Now(), outOrder, outData)
+		trends.PredictFull(c, outOrder, outData)
+	}
+	var changes *datasources.SourceReturn
+	if c.History {
+		changes = historyChanges(c, outOrder, outData)
+	}
+	writeOutputs(c, outOrder, outData, changes)
+	return outOrder, outData, changes
+}
+
+// userConfigPath returns the per-user config, it is not used in daemon mode
+//
+// $XDG_CONFIG_HOME/go-motd/config.yaml is used if it exists and no path was given.
+func userConfigPath() string {
+	if args.Daemon {
+		return ""
+	}
+	if args.UserConfig != "" {
+		return args.UserConfig
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, "go-motd", "config.yaml")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// loadConfig reads and validates the config file merged with the per-user config, then applies overrides from command line arguments
+func loadConfig() (datasources.Conf, error) {
+	c, err := datasources.NewConfWithOverlay(args.ConfigFile, userConfigPath(), args.Debug)
+	if args.Updates {
+		log.Debug("Show only updates")
+		// Set show to true
+		if u, ok := c.Modules["updates"].(*datasources.ConfUpdates); ok {
+			u.Show = &args.Updates
+			u.PadHeader = []int{0, 0}
+		}
+	}
+	return c, err
+}
+
+// applyTheme sets the colors used for rendering from the config
+func applyTheme(c *datasources.Conf) {
+	theme, err := c.Theme.Theme()
+	if err != nil {
+		log.Warnf("theme: %v", err)
+		theme = utils.DefaultTheme()
+	}
+	utils.SetTheme(theme)
+}
+
+// reloadConfig returns the config read from disk, or c if it cannot be loaded
+func reloadConfig(c *datasources.Conf) *datasources.Conf {
+	newConf, err := loadConfig()
+	if err != nil {
+		log.Errorf("config reload failed, keeping previous config: %v", err)
+		return c
+	}
+	log.Infof("config reloaded from %s", args.ConfigFile)
+	applyTheme(&newConf)
+	return &newConf
 }
 
 func runDaemon(c *datasources.Conf) {
@@ -219,13 +263,52 @@ func runDaemon(c *datasources.Conf) {
 			}
 		}
 	}()
+	var metrics metricsServer
+	if args.MetricsListen != "" {
+		srv, err := metrics.Listen(args.MetricsListen)
+		if err != nil {
+			log.Errorf("cannot serve metrics: %v", err)
+		} else {
+			defer func() {
+				if err := srv.Close(); err != nil {
+					log.Error(err)
+				}
+				if strings.HasPrefix(args.MetricsListen, "/") {
+					_ = os.Remove(args.MetricsListen)
+				}
+			}()
+		}
+	}
+	trends := loadTrends(c, nil)
+	var tracker notify.Tracker
+	var webhooks notify.Webhooks
+	var hooks notify.Hooks
+	// Wait for notifications which are being sent, running hooks are killed
+	defer webhooks.Wait()
+	defer hooks.Stop()
+	refresh := func() {
+		order, results, changes := runModules(c, trends)
+		saveTrends(c, trends)
+		doc, transitions := tracker.Update(order, results)
+		webhooks.Notify(c.Webhooks, doc, transitions)
+		hooks.Run(c, doc, transitions)
+		if args.MetricsListen == "" {
+			return
+		}
+		pageOrder, pageResults := withChanges(order, results, changes)
+		page, err := render.HTML(pageOrder, pageResults, c.ModuleWarnOnly)
+		if err != nil {
+			log.Errorf("cannot render page: %v", err)
+		}
+		metrics.Update(order, results, page)
+	}
 	log.Infof("auto-refresh every %v", args.RefreshInterval)
 	var refreshStart time.Time
 	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
 	ticker := time.NewTicker(args.RefreshInterval)
 	// Always run at startup
-	runModules(c)
+	refresh()
 	for {
 		select {
 		case <-ticker.C:
@@ -233,15 +316,21 @@ func runDaemon(c *datasources.Conf) {
 			if args.Debug {
 				refreshStart = time.Now()
 			}
-			runModules(c)
+			refresh()
 			if args.Debug {
 				log.Debugf("refresh ran in: %s", time.Now().Sub(refreshStart).String())
 			}
 		case s := <-signals:
 			switch s {
 			case syscall.SIGHUP:
-				log.Debug("SIGHUP received, refreshing")
-				runModules(c)
+				log.Debug("SIGHUP received, reloading config")
+				c = reloadConfig(c)
+				trends = loadTrends(c, trends)
+				refresh()
+				ticker.Reset(args.RefreshInterval)
+			case syscall.SIGUSR1:
+				log.Debug("SIGUSR1 received, refreshing")
+				refresh()
 				ticker.Reset(args.RefreshInterval)
 			default:
 				log.Warn("exit signal received")
@@ -254,7 +343,15 @@ func runDaemon(c *datasources.Conf) {
 func main() {
 	args.ConfigFile = defaultCfgPath
 	args.RefreshInterval, _ = time.ParseDuration(defaultRefresh)
-	arg.MustParse(&args)
+	args.Format = datasources.FormatText
+	args.Color = utils.ColorAuto
+	p := arg.MustParse(&args)
+	if !datasources.ValidFormat(args.Format) {
+		p.Fail(fmt.Sprintf("unknown format %s", args.Format))
+	}
+	if !utils.ValidColorMode(args.Color) {
+		p.Fail(fmt.Sprintf("unknown color mode %s", args.Color))
+	}
 
 	setupLogging()
 
@@ -263,13 +360,18 @@ func main() {
 		mainStart = time.Now()
 	}
 	if args.NoColors {
-		utils.NoColors = true
+		args.Color = utils.ColorNever
 	}
+	if args.CheckConfig {
+		os.Exit(checkConfig(args.ConfigFile, userConfigPath()))
+	}
+
 	// Read config file
-	c, err := datasources.NewConfFromFile(args.ConfigFile, args.Debug)
+	c, err := loadConfig()
 	if err != nil {
 		log.Warn(err)
 	}
+	applyTheme(&c)
 
 	if args.DumpConfig {
 		log.Info("Dumping config")
@@ -281,17 +383,12 @@ func main() {
 		return
 	}
 
-	if args.Updates {
-		log.Debug("Show only updates")
-		// Set show to true
-		c.Updates.Show = &args.Updates
-		c.Updates.PadHeader = []int{0, 0}
-	}
-
-	if args.Daemon {
+	if args.Check {
+		os.Exit(runCheck(&c))
+	} else if args.Daemon {
 		runDaemon(&c)
 	} else {
-		runModules(&c)
+		runModules(&c, nil)
 	}
 	// Show timing results
 	if args.Debug {
@@ -299,8 +396,34 @@ func main() {
 	}
 }
 
+// checkConfig prints all problems found in the config file at path, its conf.d fragments and
+// the per-user overlay, returns 1 if there are any
+func checkConfig(path string, overlay string) int {
+	_, err := datasources.CheckConfig(path, overlay)
+	if errs, ok := err.(datasources.ConfigErrors); ok {
+		for _, e := range errs {
+			fmt.Println(e)
+		}
+		return 1
+	}
+	files := datasources.ConfigFiles(path)
+	if overlay != "" {
+		files = append(files, overlay)
+	}
+	for _, f := range files {
+		fmt.Printf("%s: OK\n", f)
+	}
+	return 0
+}
+
 func dumpConfig(c *datasources.Conf, writeFile string) {
-	d, err := yaml.Marshal(c)
+	var d []byte
+	var err error
+	if args.ShowOrigin {
+		d, err = c.MarshalWithOrigins()
+	} else {
+		d, err = yaml.Marshal(c)
+	}
 	if err != nil {
 		log.Errorf("Config parse error: %v", err)
 		return
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+)
+
+// metricsServer serves the last collected results as Prometheus metrics and an HTML page
+type metricsServer struct {
+	mu        sync.RWMutex
+	order     []string
+	results   map[string]datasources.SourceReturn
+	page      []byte
+	collected time.Time
+}
+
+// Update replaces the served results and HTML page
+func (m *metricsServer) Update(order []string, results map[string]datasources.SourceReturn, page []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.order = order
+	m.results = results
+	m.page = page
+	m.collected = time.Now()
+}
+
+// servePage serves the HTML page of the last collected results
+func (m *metricsServer) servePage(w http.ResponseWriter, _ *http.Request) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.page == nil {
+		http.Error(w, "no data collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(m.page)
+}
+
+func (m *metricsServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.results == nil {
+		http.Error(w, "no data collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := render.Prometheus(&buf, m.order, m.results, m.collected); err != nil {
+		log.Errorf("cannot render metrics: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Listen serves metrics on /metrics and the HTML page on / in the background, an absolute path indicates a unix socket, otherwise <addr>:<port>
+func (m *metricsServer) Listen(addr string) (*http.Server, error) {
+	var network string
+	if strings.HasPrefix(addr, "/") {
+		network = "unix"
+		// Remove stale socket from a previous run
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		network = "tcp"
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	mux.HandleFunc("/{$}", m.servePage)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Errorf("metrics server stopped: %v", err)
+		}
+	}()
+	log.Infof("serving metrics on %s %s", network, addr)
+	return srv, nil
+}
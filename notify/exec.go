@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+)
+
+// hookWaitDelay is how long output is read after a hook is killed, children may keep it open
+const hookWaitDelay = time.Second
+
+// Hooks runs the on_warning, on_critical and on_recover commands of status transitions
+type Hooks struct {
+	// Cancelled by Stop to kill running hooks
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// hookEnv returns the environment of a hook, the transition is passed in MOTD_* variables
+func hookEnv(doc *render.Document, t render.Transition) []string {
+	return append(os.Environ(),
+		"MOTD_HOST="+doc.Host,
+		"MOTD_MODULE="+t.Module,
+		"MOTD_ITEM="+t.Item,
+		"MOTD_OLD_STATUS="+t.Old.String(),
+		"MOTD_NEW_STATUS="+t.New.String(),
+		"MOTD_VALUE="+t.Value,
+	)
+}
+
+// Run starts the hook of every transition in the background with sh -c, failures are logged with their output.
+// Hooks are killed if they run longer than hook_timeout.
+func (h *Hooks) Run(c *datasources.Conf, doc *render.Document, transitions []render.Transition) {
+	if h.ctx == nil {
+		h.ctx, h.cancel = context.WithCancel(context.Background())
+	}
+	for _, t := range transitions {
+		command := c.HookCommand(t.Module, t.Old, t.New)
+		if command == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(h.ctx, c.HookDeadline())
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Env = hookEnv(doc, t)
+		cmd.WaitDelay = hookWaitDelay
+		log.Debugf("hook %s: running %s", t.Key(), command)
+		h.wg.Add(1)
+		go func(key string) {
+			defer h.wg.Done()
+			defer cancel()
+			out, err := cmd.CombinedOutput()
+			if err != nil && ctx.Err() != nil {
+				err = fmt.Errorf("%w: %v", ctx.Err(), err)
+			}
+			if err != nil {
+				log.Warnf("hook %s: %v: %s", key, err, strings.TrimSpace(string(out)))
+			}
+		}(t.Key())
+	}
+}
+
+// Wait blocks until all hooks have exited
+func (h *Hooks) Wait() {
+	h.wg.Wait()
+}
+
+// Stop kills running hooks and waits for them to exit, Run can be used again afterwards
+func (h *Hooks) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+		h.ctx = nil
+	}
+	h.wg.Wait()
+}
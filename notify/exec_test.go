@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+)
+
+func TestHooks(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hooks")
+	c := datasources.Conf{}
+	c.Init()
+	c.OnCritical = `echo "global $MOTD_MODULE $MOTD_ITEM $MOTD_OLD_STATUS $MOTD_NEW_STATUS $MOTD_VALUE" >> ` + out
+	c.Modules["zfs"].Base().OnCritical = `echo "zfs $MOTD_ITEM $MOTD_VALUE" >> ` + out
+	c.OnRecover = `echo "recovered $MOTD_MODULE" >> ` + out
+	doc := &render.Document{Host: "server"}
+	transition := func(module, item string, from, to datasources.Status, value string) render.Transition {
+		return render.Transition{Module: module, Item: item, Old: from, New: to, Value: value}
+	}
+	var hooks Hooks
+	run := func(transitions ...render.Transition) string {
+		_ = os.Remove(out)
+		hooks.Run(&c, doc, transitions)
+		hooks.Wait()
+		b, _ := os.ReadFile(out)
+		return string(b)
+	}
+	if actual, expected := run(transition("systemd", "nginx.service", datasources.StatusOK, datasources.StatusCritical, "failed")),
+		"global systemd nginx.service ok critical failed\n"; actual != expected {
+		t.Errorf("got %q, expected %q", actual, expected)
+	}
+	if actual, expected := run(transition("zfs", "tank", datasources.StatusOK, datasources.StatusCritical, "FAULTED")),
+		"zfs tank FAULTED\n"; actual != expected {
+		t.Errorf("module hook: got %q, expected %q", actual, expected)
+	}
+	if actual, expected := run(transition("zfs", "tank", datasources.StatusCritical, datasources.StatusOK, "ONLINE")),
+		"recovered zfs\n"; actual != expected {
+		t.Errorf("global recover hook: got %q, expected %q", actual, expected)
+	}
+	if actual := run(transition("docker", "web", datasources.StatusOK, datasources.StatusWarning, "restarting")); actual != "" {
+		t.Errorf("ran a hook without on_warning: %q", actual)
+	}
+	// A module which times out after a critical result has not recovered
+	var tracker Tracker
+	update := func(sr datasources.SourceReturn) string {
+		_, transitions := tracker.Update([]string{"systemd"}, map[string]datasources.SourceReturn{"systemd": sr})
+		return run(transitions...)
+	}
+	update(datasources.SourceReturn{Status: datasources.StatusCritical, Items: []datasources.Item{
+		{Name: "nginx.service", Value: "failed", Status: datasources.StatusCritical},
+	}})
+	if actual := update(datasources.SourceReturn{Status: datasources.StatusUnknown, Message: "timed out", Error: context.DeadlineExceeded}); actual != "" {
+		t.Errorf("ran a hook after a timeout: %q", actual)
+	}
+}
+
+func TestHooksTimeout(t *testing.T) {
+	c := datasources.Conf{}
+	c.Init()
+	c.OnCritical = "sleep 10"
+	c.HookTimeout = 50 * time.Millisecond
+	tr := render.Transition{Module: "zfs", Old: datasources.StatusOK, New: datasources.StatusCritical}
+	var hooks Hooks
+	start := time.Now()
+	hooks.Run(&c, &render.Document{}, []render.Transition{tr})
+	hooks.Wait()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook was not killed after its timeout, took %s", elapsed)
+	}
+	// Stop kills hooks before their timeout
+	c.HookTimeout = time.Minute
+	start = time.Now()
+	hooks.Run(&c, &render.Document{}, []render.Transition{tr})
+	hooks.Stop()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook was not killed by Stop, took %s", elapsed)
+	}
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+)
+
+// Tracker keeps the results of the previous run to find status transitions
+type Tracker struct {
+	prev *render.Document
+}
+
+// Update compares the results with the previous ones and returns them as a document with the transitions,
+// there are none after the first run. Modules which did not run are kept for the next comparison.
+func (t *Tracker) Update(order []string, results map[string]datasources.SourceReturn) (*render.Document, []render.Transition) {
+	doc := render.NewDocument(order, results)
+	transitions := render.Transitions(t.prev, order, results)
+	if t.prev != nil {
+		for _, m := range t.prev.Modules {
+			if doc.Module(m.Name) == nil {
+				doc.Modules = append(doc.Modules, m)
+			}
+		}
+	}
+	t.prev = doc
+	return doc, transitions
+}
@@ -0,0 +1,217 @@
+// Package notify tells external services when the status of modules and items changes in daemon mode
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+)
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	// Hostname of the machine the modules ran on
+	Host string `json:"host"`
+	// Time the modules ran
+	Time time.Time `json:"time"`
+	// Worst status after the changes
+	Status datasources.Status `json:"status"`
+	// One line per transition, for chat relays which only show text
+	Text string `json:"text"`
+	// Changes since the previous run
+	Transitions []render.Transition `json:"transitions"`
+}
+
+// Webhooks posts status transitions to the configured webhooks
+type Webhooks struct {
+	// Client used for requests, http.DefaultClient if nil
+	Client *http.Client
+	// Time of the last notification, keyed by webhook URL and transition key
+	sent map[string]time.Time
+	// Transitions held back by the cooldown, keyed like sent
+	pending map[string]render.Transition
+	// Payloads waiting to be posted, keyed by webhook URL
+	queues map[string]*queue
+	wg     sync.WaitGroup
+}
+
+// queue posts the payloads of a webhook one at a time in the order they were added
+type queue struct {
+	mu      sync.Mutex
+	bodies  [][]byte
+	hook    datasources.Webhook
+	running bool
+}
+
+// Notify posts the transitions found in doc to every webhook in the background, it should be called after every run.
+//
+// Transitions of a module or item notified within the cooldown of a webhook are held back and sent with the first
+// run after it ends, only the latest status is sent. They are dropped if the status went back to the one notified.
+func (w *Webhooks) Notify(hooks []datasources.Webhook, doc *render.Document, transitions []render.Transition) {
+	if w.sent == nil {
+		w.sent = make(map[string]time.Time)
+		w.pending = make(map[string]render.Transition)
+		w.queues = make(map[string]*queue)
+	}
+	for _, h := range hooks {
+		prefix := h.URL + " "
+		// Transitions are merged with the ones held back, the current ones are sent first
+		var keys []string
+		seen := make(map[string]bool)
+		for _, t := range transitions {
+			key := prefix + t.Key()
+			if p, ok := w.pending[key]; ok {
+				t.Old = p.Old
+			}
+			w.pending[key] = t
+			if t.Old == t.New {
+				delete(w.pending, key)
+			}
+			keys = append(keys, key)
+			seen[key] = true
+		}
+		var held []string
+		for key := range w.pending {
+			if strings.HasPrefix(key, prefix) && !seen[key] {
+				held = append(held, key)
+			}
+		}
+		sort.Strings(held)
+		var send []render.Transition
+		for _, key := range append(keys, held...) {
+			t, ok := w.pending[key]
+			if !ok {
+				continue
+			}
+			if last, ok := w.sent[key]; ok && doc.Time.Sub(last) < h.NotifyCooldown() {
+				log.Debugf("webhook %s: %s in cooldown", h.URL, t.Key())
+				continue
+			}
+			w.sent[key] = doc.Time
+			delete(w.pending, key)
+			send = append(send, t)
+		}
+		if len(send) == 0 {
+			continue
+		}
+		lines := make([]string, len(send))
+		for i, t := range send {
+			lines[i] = doc.Host + ": " + t.String()
+		}
+		body, err := json.Marshal(Payload{Host: doc.Host, Time: doc.Time, Status: doc.Status, Text: strings.Join(lines, "\n"), Transitions: send})
+		if err != nil {
+			log.Errorf("webhook %s: %v", h.URL, err)
+			continue
+		}
+		w.enqueue(h, body)
+	}
+}
+
+// enqueue adds body to the queue of the webhook and starts posting if it is not already
+func (w *Webhooks) enqueue(h datasources.Webhook, body []byte) {
+	q, ok := w.queues[h.URL]
+	if !ok {
+		q = &queue{}
+		w.queues[h.URL] = q
+	}
+	w.wg.Add(1)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.bodies = append(q.bodies, body)
+	// Use the latest config after a reload
+	q.hook = h
+	if !q.running {
+		q.running = true
+		go w.drain(q)
+	}
+}
+
+// drain posts the payloads in q until it is empty
+func (w *Webhooks) drain(q *queue) {
+	for {
+		q.mu.Lock()
+		if len(q.bodies) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		body, h := q.bodies[0], q.hook
+		q.bodies = q.bodies[1:]
+		q.mu.Unlock()
+		if err := w.post(h, body); err != nil {
+			log.Warnf("webhook %s: %v", h.URL, err)
+		}
+		w.wg.Done()
+	}
+}
+
+// Wait blocks until all notifications were sent or failed
+func (w *Webhooks) Wait() {
+	w.wg.Wait()
+}
+
+// retryError is a failed request which may succeed if it is retried
+type retryError struct {
+	err error
+}
+
+func (e retryError) Error() string {
+	return e.err.Error()
+}
+
+// post sends body to the webhook, retrying failed requests with exponential backoff
+func (w *Webhooks) post(h datasources.Webhook, body []byte) error {
+	backoff := h.RetryBackoff()
+	for attempt := 0; ; attempt++ {
+		err := w.postOnce(h, body)
+		if _, retry := err.(retryError); !retry || attempt >= h.MaxRetries() {
+			return err
+		}
+		log.Debugf("webhook %s: %v, retrying in %s", h.URL, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// postOnce sends a single request, network errors, 429 and 5xx responses can be retried
+func (w *Webhooks) postOnce(h datasources.Webhook, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.RequestTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-motd")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return retryError{err}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+	return fmt.Errorf("server returned %s", resp.Status)
+}
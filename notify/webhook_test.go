@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var received []Payload
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		// The first request fails and is retried
+		if attempts == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing header, got %v", r.Header)
+		}
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		received = append(received, p)
+	}))
+	defer srv.Close()
+
+	retries := 1
+	hooks := []datasources.Webhook{{
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Retries:  &retries,
+		Backoff:  20 * time.Millisecond,
+		Cooldown: time.Minute,
+	}}
+	var tracker Tracker
+	var w Webhooks
+	start := time.Now()
+	run := func(offset time.Duration, nginx datasources.Status, db datasources.Status) {
+		results := map[string]datasources.SourceReturn{"systemd": {Status: datasources.StatusOK, Items: []datasources.Item{
+			{Name: "nginx.service", Value: nginx.String(), Status: nginx},
+			{Name: "db.service", Value: db.String(), Status: db},
+		}}}
+		doc, transitions := tracker.Update([]string{"systemd"}, results)
+		doc.Time = start.Add(offset)
+		w.Notify(hooks, doc, transitions)
+	}
+	ok, crit := datasources.StatusOK, datasources.StatusCritical
+	run(0, ok, ok)
+	// The second payload is queued while the first one is retried
+	run(time.Second, crit, ok)
+	run(2*time.Second, crit, crit)
+	// Changes within the cooldown are held back, flapping back to the notified status is not sent
+	run(3*time.Second, ok, crit)
+	run(4*time.Second, crit, crit)
+	run(5*time.Second, ok, crit)
+	run(2*time.Minute, ok, crit)
+	w.Wait()
+
+	expected := []string{
+		"nginx.service: ok → critical",
+		"db.service: ok → critical",
+		"nginx.service: critical → ok",
+	}
+	if attempts != len(expected)+1 || len(received) != len(expected) {
+		t.Fatalf("got %d attempts and payloads %+v, expected %d", attempts, received, len(expected))
+	}
+	for i, p := range received {
+		if len(p.Transitions) != 1 {
+			t.Fatalf("payload %d: got %+v", i, p)
+		}
+		tr := p.Transitions[0]
+		if actual := tr.Item + ": " + tr.Old.String() + " → " + tr.New.String(); actual != expected[i] {
+			t.Errorf("payload %d: got %s, expected %s", i, actual, expected[i])
+		}
+	}
+	if expected := received[0].Host + ": systemd nginx.service: ok → critical (critical)"; received[0].Text != expected {
+		t.Errorf("got text %q, expected %q", received[0].Text, expected)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// renderFormat renders the results of modules in order using format, text is at most width wide unless it is 0.
+// changes are shown first in text and HTML if they are not nil.
+func renderFormat(c *datasources.Conf, format string, width int, order []string, results map[string]datasources.SourceReturn, changes *datasources.SourceReturn) ([]byte, error) {
+	switch format {
+	case datasources.FormatText:
+		order, results := withChanges(order, results, changes)
+		if c.Template != "" {
+			return renderTemplate(c, order, results)
+		}
+		return []byte(renderText(c, width, order, results)), nil
+	case datasources.FormatJSON:
+		return render.JSON(order, results)
+	case datasources.FormatHTML:
+		order, results := withChanges(order, results, changes)
+		return render.HTML(order, results, c.ModuleWarnOnly)
+	case datasources.FormatWaybar:
+		// The tooltip is never colored
+		noColors := utils.NoColors
+		utils.NoColors = true
+		tooltip := renderText(c, 0, order, results)
+		utils.NoColors = noColors
+		return render.Waybar(order, results, tooltip)
+	case datasources.FormatTmux:
+		return render.Tmux(order, results), nil
+	}
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+// textWidth returns the maximum width of text written to f, the smaller of max_width and the terminal width.
+// f is nil for regular files.
+func textWidth(c *datasources.Conf, f *os.File) int {
+	width := c.MaxWidth
+	if f == nil {
+		return width
+	}
+	if tw := utils.TerminalWidth(f); tw > 0 && (width == 0 || tw < width) {
+		width = tw
+	}
+	return width
+}
+
+// useColors returns true if format should be colored according to mode, f is nil for regular files.
+//
+// tmux style codes are interpreted by tmux rather than the terminal, so in auto mode they are used unless NO_COLOR is set.
+func useColors(format string, mode string, f *os.File) bool {
+	if format == datasources.FormatTmux && mode == utils.ColorAuto {
+		return os.Getenv("NO_COLOR") == ""
+	}
+	return utils.UseColors(mode, f)
+}
+
+// outputs returns the files to write, --output replaces the outputs in the config file which are only used in daemon mode.
+// Nothing is returned if output should be written to stdout.
+func outputs(c *datasources.Conf) []datasources.Output {
+	if args.Output != "" {
+		return []datasources.Output{{Path: args.Output, Format: args.Format, Color: args.Color}}
+	}
+	if args.Daemon {
+		return c.Outputs
+	}
+	return nil
+}
+
+// writeOutputs renders and writes the results and changes to every output, or to stdout if there are none
+func writeOutputs(c *datasources.Conf, order []string, results map[string]datasources.SourceReturn, changes *datasources.SourceReturn) {
+	targets := outputs(c)
+	if len(targets) == 0 {
+		utils.NoColors = !useColors(args.Format, args.Color, os.Stdout)
+		out, err := renderFormat(c, args.Format, textWidth(c, os.Stdout), order, results, changes)
+		if err != nil {
+			log.Errorf("cannot render output: %v", err)
+			return
+		}
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+	// Render each format once with and without colors
+	type renderKey struct {
+		format string
+		colors bool
+	}
+	rendered := make(map[renderKey][]byte)
+	for _, o := range targets {
+		colorMode := o.Color
+		if colorMode == "" {
+			colorMode = args.Color
+		}
+		key := renderKey{o.FileFormat(), useColors(o.FileFormat(), colorMode, nil)}
+		out, ok := rendered[key]
+		if !ok {
+			var err error
+			utils.NoColors = !key.colors
+			out, err = renderFormat(c, key.format, textWidth(c, nil), order, results, changes)
+			if err != nil {
+				log.Errorf("cannot render %s: %v", o.Path, err)
+				continue
+			}
+			rendered[key] = out
+		}
+		mode, err := o.FileMode()
+		if err != nil {
+			log.Errorf("cannot write %s: %v", o.Path, err)
+			continue
+		}
+		uid, gid, err := o.IDs()
+		if err != nil {
+			log.Errorf("cannot write %s: %v", o.Path, err)
+			continue
+		}
+		if err := utils.WriteFileAtomic(o.Path, out, mode, uid, gid); err != nil {
+			log.Errorf("cannot write %s: %v", o.Path, err)
+		}
+	}
+}
+
+// renderTemplate renders the results using the template in the config
+func renderTemplate(c *datasources.Conf, order []string, results map[string]datasources.SourceReturn) ([]byte, error) {
+	tmpl, err := c.TemplateText()
+	if err != nil {
+		return nil, err
+	}
+	text := func(name string) string {
+		sr, ok := results[name]
+		if !ok {
+			return ""
+		}
+		return render.Text(&sr, c.ModuleBase(name), c.ModuleWarnOnly(name))
+	}
+	return render.Template(tmpl, order, results, text)
+}
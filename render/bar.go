@@ -0,0 +1,59 @@
+package render
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// defaultBarWidth is used if bar_width is not set
+const defaultBarWidth = 20
+
+// barEighths are Unicode blocks filling 1/8 to 7/8 of a cell
+var barEighths = []rune("▏▎▍▌▋▊▉")
+
+// metricStatus returns the status of m according to its thresholds, informational if it has none
+func metricStatus(m datasources.Metric) datasources.Status {
+	switch {
+	case m.Warn == 0 && m.Crit == 0:
+		return datasources.StatusInfo
+	case m.Value >= m.Crit:
+		return datasources.StatusCritical
+	case m.Value >= m.Warn:
+		return datasources.StatusWarning
+	}
+	return datasources.StatusOK
+}
+
+// Bar draws a usage bar of m width cells wide, the filled part is colored by the thresholds of m.
+//
+// Unicode bars use partial blocks for a resolution of 1/8 of a cell, ASCII bars are enclosed in brackets.
+func Bar(m datasources.Metric, width int, ascii bool) string {
+	if width <= 0 {
+		width = defaultBarWidth
+	}
+	ratio := math.Min(math.Max(m.Value/m.Max, 0), 1)
+	var filled, empty string
+	if ascii {
+		inner := max(width-2, 1)
+		n := int(math.Round(ratio * float64(inner)))
+		filled, empty = strings.Repeat("#", n), strings.Repeat("-", inner-n)
+	} else {
+		eighths := int(math.Round(ratio * float64(width*8)))
+		n := eighths / 8
+		filled = strings.Repeat("█", n)
+		if part := eighths % 8; part > 0 {
+			filled += string(barEighths[part-1])
+			n++
+		}
+		empty = strings.Repeat("░", width-n)
+	}
+	if filled != "" {
+		filled = Colorize(metricStatus(m), filled)
+	}
+	if ascii {
+		return "[" + filled + empty + "]"
+	}
+	return filled + empty
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+func TestBar(t *testing.T) {
+	defer func(noColors bool) { utils.NoColors = noColors }(utils.NoColors)
+	utils.NoColors = true
+	m := datasources.Metric{Name: "used_percent", Value: 45, Unit: "%", Max: 100, Warn: 70, Crit: 90}
+	tests := []struct {
+		value    float64
+		width    int
+		ascii    bool
+		expected string
+	}{
+		{45, 10, false, "████▌░░░░░"},
+		{45, 12, true, "[#####-----]"},
+		{0, 4, false, "░░░░"},
+		{150, 4, true, "[##]"},
+		{100, 0, false, "████████████████████"},
+	}
+	for _, tt := range tests {
+		m.Value = tt.value
+		if actual := Bar(m, tt.width, tt.ascii); actual != tt.expected {
+			t.Errorf("Bar(%v, %d, %v) = %q, expected %q", tt.value, tt.width, tt.ascii, actual, tt.expected)
+		}
+	}
+	utils.NoColors = false
+	m.Value = 95
+	if actual, expected := Bar(m, 2, false), utils.Err("█▉"); actual != expected {
+		t.Errorf("got %q, expected %q", actual, expected)
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// ChangesTitle is the title of the result listing changes
+const ChangesTitle = "Changes"
+
+// Change is a difference between the previous and current results of a module
+type Change struct {
+	// Module name
+	Module string
+	// Item or metric name, empty if the status of the module changed
+	Name string
+	// Description of the change, for example "ok → critical (exited)"
+	Description string
+	// Status after the change, informational for recoveries, removed items and metrics
+	Status datasources.Status
+}
+
+// Changes compares the results of modules in order with the previous document, modules which were not in it are skipped.
+//
+// Items with a changed status, new items which are not OK and removed items are reported, the module status is
+// reported if none of its items changed. Module metrics without a unit, such as pending updates, are counts and
+// changes to their value are reported too. Items are only compared if the module returned its data in both runs,
+// otherwise only the change of the module status is reported.
+func Changes(prev *Document, order []string, results map[string]datasources.SourceReturn) []Change {
+	if prev == nil {
+		return nil
+	}
+	var changes []Change
+	for _, k := range order {
+		sr, ok := results[k]
+		pm := prev.Module(k)
+		if !ok || pm == nil || sr.Status == datasources.StatusInfo {
+			continue
+		}
+		var moduleChanges []Change
+		if compareItems(pm, &sr) {
+			moduleChanges = itemChanges(k, diffItems(pm.Items, sr.Items))
+		}
+		if len(moduleChanges) == 0 && sr.Status != pm.Status {
+			moduleChanges = append(moduleChanges, Change{
+				Module:      k,
+				Description: fmt.Sprintf("%s → %s", pm.Status, sr.Status),
+				Status:      changeStatus(sr.Status),
+			})
+		}
+		for _, m := range sr.Metrics {
+			for _, old := range pm.Metrics {
+				if m.Unit != "" || old.Name != m.Name || old.Value == m.Value {
+					continue
+				}
+				moduleChanges = append(moduleChanges, Change{
+					Module:      k,
+					Name:        m.Name,
+					Description: fmt.Sprintf("%g → %g (%+g)", old.Value, m.Value, m.Value-old.Value),
+					Status:      datasources.StatusInfo,
+				})
+			}
+		}
+		changes = append(changes, moduleChanges...)
+	}
+	return changes
+}
+
+// itemDiff is a named item which was added, removed or whose status changed between two runs
+type itemDiff struct {
+	// Item in the previous run, nil if it was added
+	old *datasources.Item
+	// Item in the current run, nil if it was removed
+	cur *datasources.Item
+}
+
+// hasData is the same as SourceReturn.HasData for a module of a previous document
+func (m *Module) hasData() bool {
+	return m.Status != datasources.StatusUnavailable && m.Error == "" && (m.Status != datasources.StatusUnknown || len(m.Items) > 0)
+}
+
+// compareItems returns true if the items of a module can be compared, they cannot if it did not return its data
+// in either run
+func compareItems(pm *Module, sr *datasources.SourceReturn) bool {
+	return sr.HasData() && pm.hasData()
+}
+
+// diffItems returns the named items which were added, removed or whose status changed, in the order of items
+// followed by the removed items
+func diffItems(prevItems []datasources.Item, items []datasources.Item) []itemDiff {
+	var diffs []itemDiff
+	seen := make(map[string]bool)
+	for i := range items {
+		it := &items[i]
+		if it.Name == "" {
+			continue
+		}
+		seen[it.Name] = true
+		var old *datasources.Item
+		for j := range prevItems {
+			if prevItems[j].Name == it.Name {
+				old = &prevItems[j]
+				break
+			}
+		}
+		if old == nil || old.Status != it.Status {
+			diffs = append(diffs, itemDiff{old, it})
+		}
+	}
+	for i := range prevItems {
+		if old := &prevItems[i]; old.Name != "" && !seen[old.Name] {
+			diffs = append(diffs, itemDiff{old, nil})
+		}
+	}
+	return diffs
+}
+
+// itemChanges describes the item differences of module, new items are only reported if they are not OK
+func itemChanges(module string, diffs []itemDiff) []Change {
+	var changes []Change
+	for _, d := range diffs {
+		switch {
+		case d.cur == nil:
+			changes = append(changes, Change{module, d.old.Name, "removed", datasources.StatusInfo})
+		case d.old == nil:
+			if d.cur.Status.Worse(datasources.StatusOK) {
+				changes = append(changes, Change{module, d.cur.Name, fmt.Sprintf("new, %s (%s)", d.cur.Status, d.cur.Value+d.cur.Unit), d.cur.Status})
+			}
+		default:
+			changes = append(changes, Change{module, d.cur.Name, fmt.Sprintf("%s → %s (%s)", d.old.Status, d.cur.Status, d.cur.Value+d.cur.Unit), changeStatus(d.cur.Status)})
+		}
+	}
+	return changes
+}
+
+// changeStatus returns status if it is worse than OK, otherwise informational
+func changeStatus(status datasources.Status) datasources.Status {
+	if status.Worse(datasources.StatusOK) {
+		return status
+	}
+	return datasources.StatusInfo
+}
+
+// ChangesResult returns the changes as a result which can be rendered like a module, since is the time of the previous document
+func ChangesResult(since *Document, changes []Change) datasources.SourceReturn {
+	sr := datasources.SourceReturn{
+		Title:   ChangesTitle,
+		Status:  datasources.StatusInfo,
+		Message: "since " + since.Time.Local().Format("Jan 2 15:04"),
+	}
+	for _, ch := range changes {
+		name := ch.Module
+		if ch.Name != "" {
+			name += " " + ch.Name
+		}
+		sr.AddItem(name, ch.Description, ch.Status)
+	}
+	return sr
+}
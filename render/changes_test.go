@@ -0,0 +1,56 @@
+package render
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestChanges(t *testing.T) {
+	prev := &Document{Modules: []Module{
+		{Name: "sysinfo", Status: datasources.StatusInfo, Items: []datasources.Item{{Name: "Load", Value: "1", Status: datasources.StatusInfo}}},
+		{Name: "docker", Status: datasources.StatusOK, Items: []datasources.Item{
+			{Name: "web", Value: "up", Status: datasources.StatusOK},
+			{Name: "db", Value: "exited", Status: datasources.StatusCritical},
+			{Name: "old", Value: "up", Status: datasources.StatusOK},
+		}},
+		{Name: "updates", Status: datasources.StatusOK, Metrics: []datasources.Metric{{Name: "pending", Value: 3}}},
+		{Name: "podman", Status: datasources.StatusUnavailable},
+		{Name: "systemd", Status: datasources.StatusCritical, Items: []datasources.Item{{Name: "nginx", Value: "failed", Status: datasources.StatusCritical}}},
+		{Name: "btrfs", Status: datasources.StatusUnknown, Error: "exit status 1"},
+	}}
+	results := map[string]datasources.SourceReturn{
+		"sysinfo": {Status: datasources.StatusInfo, Items: []datasources.Item{{Name: "Load", Value: "2", Status: datasources.StatusInfo}}},
+		"docker": {Status: datasources.StatusWarning, Items: []datasources.Item{
+			{Name: "web", Value: "exited", Status: datasources.StatusCritical},
+			{Name: "db", Value: "up", Status: datasources.StatusOK},
+			{Name: "new", Value: "dead", Status: datasources.StatusCritical},
+			{Name: "new_ok", Value: "up", Status: datasources.StatusOK},
+		}},
+		"updates": {Status: datasources.StatusWarning, Metrics: []datasources.Metric{{Name: "pending", Value: 10}}},
+		"podman":  {Status: datasources.StatusOK, Items: []datasources.Item{{Name: "pod", Value: "up", Status: datasources.StatusOK}}},
+		"zfs":     {Status: datasources.StatusCritical},
+		"systemd": {Status: datasources.StatusUnknown, Message: "timed out", Error: context.DeadlineExceeded},
+		"btrfs":   {Status: datasources.StatusOK, Items: []datasources.Item{{Name: "/", Value: "50%", Status: datasources.StatusOK}}},
+	}
+	expected := []Change{
+		{"docker", "web", "ok → critical (exited)", datasources.StatusCritical},
+		{"docker", "db", "critical → ok (up)", datasources.StatusInfo},
+		{"docker", "new", "new, critical (dead)", datasources.StatusCritical},
+		{"docker", "old", "removed", datasources.StatusInfo},
+		{"updates", "", "ok → warning", datasources.StatusWarning},
+		{"updates", "pending", "3 → 10 (+7)", datasources.StatusInfo},
+		{"podman", "", "unavailable → ok", datasources.StatusInfo},
+		{"systemd", "", "critical → unknown", datasources.StatusUnknown},
+		{"btrfs", "", "unknown → ok", datasources.StatusInfo},
+	}
+	actual := Changes(prev, []string{"sysinfo", "docker", "updates", "podman", "zfs", "systemd", "btrfs"}, results)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got:\n%v\nexpected:\n%v", actual, expected)
+	}
+	if actual := Changes(nil, []string{"docker"}, results); actual != nil {
+		t.Errorf("expected no changes without a previous document, got %v", actual)
+	}
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// statusClasses are the CSS classes of each status, they match the theme styles used by Colorize
+var statusClasses = map[datasources.Status]string{
+	datasources.StatusInfo:        "info",
+	datasources.StatusOK:          "good",
+	datasources.StatusUnavailable: "muted",
+	datasources.StatusUnknown:     "warn",
+	datasources.StatusWarning:     "warn",
+	datasources.StatusCritical:    "err",
+}
+
+// htmlPage is the data of htmlTemplate
+type htmlPage struct {
+	Host     string
+	Time     time.Time
+	Status   string
+	Class    string
+	CSS      template.CSS
+	Sections []htmlSection
+}
+
+// htmlSection is a module in htmlPage
+type htmlSection struct {
+	Name   string
+	Title  string
+	Status string
+	Class  string
+	Items  []htmlItem
+}
+
+// htmlItem is a row of htmlSection
+type htmlItem struct {
+	Name  string
+	Value string
+	Class string
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Host}}: {{.Status}}</title>
+<style>
+body { background: #1c1c1c; color: #d0d0d0; font-family: monospace; margin: 1em; }
+h1 { font-size: 1.2em; }
+section { margin-bottom: 1em; }
+h2 { font-size: 1em; margin: 0; }
+table { border-collapse: collapse; margin-left: 1ch; }
+th { font-weight: normal; padding: 0 1ch 0 0; text-align: left; vertical-align: top; }
+td { padding: 0; white-space: pre-wrap; }
+footer { opacity: 0.7; }
+{{.CSS}}
+</style>
+</head>
+<body>
+<h1>{{.Host}}: <span class="{{.Class}}">{{.Status}}</span></h1>
+{{- range .Sections}}
+<section id="{{.Name}}">
+{{- if .Title}}
+<h2><span class="label">{{.Title}}</span>: <span class="{{.Class}}">{{.Status}}</span></h2>
+{{- end}}
+{{- if .Items}}
+<table>
+{{- range .Items}}
+<tr><th class="label">{{.Name}}</th><td class="{{.Class}}">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</section>
+{{- end}}
+<footer>Generated {{.Time.Format "2006-01-02 15:04:05 MST"}}</footer>
+</body>
+</html>
+`))
+
+// themeCSS returns a CSS rule for every style of the current theme
+func themeCSS() template.CSS {
+	t := utils.CurrentTheme()
+	var sb strings.Builder
+	for _, s := range []struct {
+		class string
+		style utils.Style
+	}{
+		{"good", t.Good},
+		{"warn", t.Warn},
+		{"err", t.Err},
+		{"info", t.Info},
+		{"label", t.Label},
+		{"muted", t.Muted},
+	} {
+		if css := s.style.CSS(); css != "" {
+			_, _ = fmt.Fprintf(&sb, ".%s { %s; }\n", s.class, css)
+		}
+	}
+	return template.CSS(sb.String())
+}
+
+// HTML renders the results of modules in order as a self-contained HTML page colored with the current theme.
+//
+// OK items of a module are hidden if warnOnly returns true for its name, like in the text format.
+func HTML(order []string, results map[string]datasources.SourceReturn, warnOnly func(name string) bool) ([]byte, error) {
+	page := htmlPage{Time: time.Now(), CSS: themeCSS()}
+	page.Host, _ = os.Hostname()
+	status := datasources.StatusInfo
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok {
+			continue
+		}
+		if sr.Status.Worse(status) {
+			status = sr.Status
+		}
+		section := htmlSection{Name: k, Title: sr.Title, Status: StatusText(&sr), Class: statusClasses[sr.Status]}
+		for _, it := range sr.Items {
+			if warnOnly(k) && it.Status == datasources.StatusOK {
+				continue
+			}
+			section.Items = append(section.Items, htmlItem{Name: it.Name, Value: it.Value + it.Unit, Class: statusClasses[it.Status]})
+		}
+		page.Sections = append(page.Sections, section)
+	}
+	page.Class = statusClasses[status]
+	page.Status = statusLabels[status]
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
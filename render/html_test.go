@@ -0,0 +1,41 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestHTML(t *testing.T) {
+	results := map[string]datasources.SourceReturn{
+		"zfs": {Title: "ZFS", Status: datasources.StatusWarning, Items: []datasources.Item{
+			{Name: "tank", Value: "80", Unit: "%", Status: datasources.StatusWarning},
+			{Name: "backup", Value: "10", Unit: "%", Status: datasources.StatusOK},
+		}},
+		"docker": {Title: "Docker", Status: datasources.StatusOK, Items: []datasources.Item{
+			{Name: "<script>", Value: "up", Status: datasources.StatusOK},
+		}},
+	}
+	out, err := HTML([]string{"zfs", "docker"}, results, func(name string) bool { return name == "zfs" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(out)
+	for _, s := range []string{
+		`<h1>`,
+		`<span class="warn">Warning</span></h1>`,
+		`<section id="zfs">`,
+		`<h2><span class="label">ZFS</span>: <span class="warn">Warning</span></h2>`,
+		`<tr><th class="label">tank</th><td class="warn">80%</td></tr>`,
+		`<tr><th class="label">&lt;script&gt;</th><td class="good">up</td></tr>`,
+		`.err { font-weight: bold; color: #cd0000; }`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("%q not found in:\n%s", s, page)
+		}
+	}
+	if strings.Contains(page, "backup") {
+		t.Errorf("OK item shown with warnings only:\n%s", page)
+	}
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// Document is the machine-readable output of a run
+type Document struct {
+	// Hostname of the machine the modules ran on
+	Host string `json:"host"`
+	// Time the document was generated
+	Time time.Time `json:"time"`
+	// Worst status of all modules
+	Status datasources.Status `json:"status"`
+	// Modules in display order
+	Modules []Module `json:"modules"`
+}
+
+// Module is the machine-readable result of a single module
+type Module struct {
+	Name     string               `json:"name"`
+	Title    string               `json:"title,omitempty"`
+	Status   datasources.Status   `json:"status"`
+	Message  string               `json:"message,omitempty"`
+	Items    []datasources.Item   `json:"items"`
+	Metrics  []datasources.Metric `json:"metrics,omitempty"`
+	Trend    []float64            `json:"trend,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	Duration float64              `json:"duration_ms"`
+}
+
+// NewDocument builds a document from the results of modules in order
+func NewDocument(order []string, results map[string]datasources.SourceReturn) *Document {
+	doc := Document{Time: time.Now(), Status: datasources.StatusInfo, Modules: make([]Module, 0, len(order))}
+	doc.Host, _ = os.Hostname()
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok {
+			continue
+		}
+		m := Module{
+			Name:     k,
+			Title:    sr.Title,
+			Status:   sr.Status,
+			Message:  sr.Message,
+			Items:    sr.Items,
+			Metrics:  sr.Metrics,
+			Trend:    sr.Trend,
+			Duration: float64(sr.Time.Microseconds()) / 1000,
+		}
+		if m.Items == nil {
+			m.Items = []datasources.Item{}
+		}
+		if sr.Error != nil {
+			m.Error = sr.Error.Error()
+		}
+		if m.Status.Worse(doc.Status) {
+			doc.Status = m.Status
+		}
+		doc.Modules = append(doc.Modules, m)
+	}
+	return &doc
+}
+
+// JSON renders the results of modules in order as an indented JSON document
+func JSON(order []string, results map[string]datasources.SourceReturn) ([]byte, error) {
+	b, err := json.MarshalIndent(NewDocument(order, results), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
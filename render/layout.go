@@ -0,0 +1,185 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// Default padding used when a module does not set pad_header or pad_content
+var (
+	// autoHeaderPad is the number of spaces between the widest header label of a column and the colon
+	autoHeaderPad = 1
+	// defaultContentPad is the indentation of content lines and the spaces after the widest label
+	defaultContentPad = []int{1, 0}
+)
+
+// Line is a label and value pair, lines without a label only show the value
+type Line struct {
+	Label string
+	Value string
+}
+
+// Block is the output of a module before layout
+type Block struct {
+	// Header lines, the title or all items if the module has no title
+	Header []Line
+	// Content lines are shown below the header
+	Content []Line
+	// PadHeader is the padding before and after the header labels, if nil they are aligned with the other blocks in the same column
+	PadHeader []int
+	// PadContent is the padding before and after the content labels, [1, 0] if nil
+	PadContent []int
+}
+
+// HeaderWidth returns the visible width of the widest header label
+func (b *Block) HeaderWidth() int {
+	return labelWidth(b.Header)
+}
+
+// Lines lays out the block, header labels are padded to width unless PadHeader is set
+func (b *Block) Lines(width int) []string {
+	var out []string
+	if len(b.PadHeader) == 2 {
+		out = appendLines(out, b.Header, b.PadHeader[0], labelWidth(b.Header)+b.PadHeader[1])
+	} else {
+		out = appendLines(out, b.Header, 0, width+autoHeaderPad)
+	}
+	pad := b.PadContent
+	if len(pad) != 2 {
+		pad = defaultContentPad
+	}
+	return appendLines(out, b.Content, pad[0], labelWidth(b.Content)+pad[1])
+}
+
+// String lays out the block on its own
+func (b *Block) String() string {
+	return strings.Join(b.Lines(b.HeaderWidth()), "\n")
+}
+
+// labelWidth returns the visible width of the widest label in lines
+func labelWidth(lines []Line) (width int) {
+	for _, l := range lines {
+		if w := utils.VisibleWidth(l.Label); w > width {
+			width = w
+		}
+	}
+	return
+}
+
+// appendLines appends lines indented by indent with their labels padded to width
+func appendLines(out []string, lines []Line, indent int, width int) []string {
+	prefix := strings.Repeat(" ", indent)
+	for _, l := range lines {
+		if l.Label == "" {
+			out = append(out, prefix+l.Value)
+		} else {
+			out = append(out, prefix+utils.PadRight(l.Label, width)+": "+l.Value)
+		}
+	}
+	return out
+}
+
+// Layout arranges blocks in order, or in columns as defined by colDef separated by colPad spaces.
+//
+// Header labels of blocks in the same column are aligned, blocks missing from blocks are skipped.
+// Rows wider than maxWidth are stacked vertically, 0 means unlimited.
+func Layout(order []string, blocks map[string]*Block, colDef [][]datasources.Column, colPad int, maxWidth int) string {
+	if len(colDef) == 0 {
+		colDef = make([][]datasources.Column, len(order))
+		for i, k := range order {
+			colDef[i] = []datasources.Column{{Name: k}}
+		}
+	}
+	// Widest header label of each column across all rows
+	var widths []int
+	for _, row := range colDef {
+		i := 0
+		for _, col := range row {
+			b, ok := blocks[col.Name]
+			if !ok {
+				continue
+			}
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(b.PadHeader) != 2 && b.HeaderWidth() > widths[i] {
+				widths[i] = b.HeaderWidth()
+			}
+			i++
+		}
+	}
+	var sb strings.Builder
+	for _, row := range colDef {
+		var cols []layoutColumn
+		for _, col := range row {
+			b, ok := blocks[col.Name]
+			if !ok {
+				continue
+			}
+			cols = append(cols, layoutColumn{block: b, lines: b.Lines(widths[len(cols)]), width: col.Width, align: col.Align})
+		}
+		lines := joinColumns(cols, colPad)
+		if maxWidth > 0 && len(cols) > 1 && linesWidth(lines) > maxWidth {
+			// Stack the columns, their headers are aligned with the first column
+			lines = nil
+			for _, col := range cols {
+				col.lines = col.block.Lines(max(widths[0], col.block.HeaderWidth()))
+				lines = append(lines, joinColumns([]layoutColumn{col}, 0)...)
+			}
+		}
+		for _, line := range lines {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// layoutColumn is a laid out block in a row
+type layoutColumn struct {
+	block *Block
+	lines []string
+	// Minimum width and alignment from col_def
+	width int
+	align string
+}
+
+// linesWidth returns the visible width of the widest line
+func linesWidth(lines []string) (width int) {
+	for _, l := range lines {
+		width = max(width, utils.VisibleWidth(l))
+	}
+	return
+}
+
+// joinColumns places cols side by side, each column is aligned within its width followed by pad spaces
+func joinColumns(cols []layoutColumn, pad int) []string {
+	var height int
+	widths := make([]int, len(cols))
+	for i, col := range cols {
+		height = max(height, len(col.lines))
+		widths[i] = max(col.width, linesWidth(col.lines))
+	}
+	out := make([]string, height)
+	for row := range out {
+		var sb strings.Builder
+		for i, col := range cols {
+			var l string
+			if row < len(col.lines) {
+				l = col.lines[row]
+			}
+			switch col.align {
+			case datasources.AlignRight:
+				l = utils.PadLeft(l, widths[i])
+			case datasources.AlignCenter:
+				l = utils.PadCenter(l, widths[i])
+			default:
+				l = utils.PadRight(l, widths[i])
+			}
+			sb.WriteString(l + strings.Repeat(" ", pad))
+		}
+		out[row] = strings.TrimRight(sb.String(), " ")
+	}
+	return out
+}
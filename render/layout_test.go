@@ -0,0 +1,52 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestLayout(t *testing.T) {
+	blocks := map[string]*Block{
+		"info": {Header: []Line{{"Distro", "Arch"}, {"Kernel", "6.9"}}},
+		"zfs": {
+			Header:  []Line{{"ZFS", "\033[1;33mWarning\033[0m"}},
+			Content: []Line{{"tank", "\033[1;33m80%\033[0m"}, {"backup", "10%"}},
+		},
+		"docker": {Header: []Line{{"Docker", "OK"}}, Content: []Line{{"日本", "up"}}},
+		"manual": {Header: []Line{{"Manual", "OK"}}, PadHeader: []int{2, 0}},
+	}
+	expected := "Distro : Arch\n" +
+		"Kernel : 6.9\n" +
+		"ZFS    : \033[1;33mWarning\033[0m    Docker : OK\n" +
+		" tank  : \033[1;33m80%\033[0m         日本: up\n" +
+		" backup: 10%\n" +
+		"  Manual: OK\n"
+	colDef := [][]datasources.Column{{{Name: "info"}}, {{Name: "zfs"}, {Name: "missing"}, {Name: "docker"}}, {{Name: "manual"}}}
+	if actual := Layout(nil, blocks, colDef, 4, 0); actual != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", actual, expected)
+	}
+	expected = "ZFS    : \033[1;33mWarning\033[0m\n tank  : \033[1;33m80%\033[0m\n backup: 10%\nDocker : OK\n 日本: up\n"
+	if actual := Layout([]string{"zfs", "docker"}, blocks, nil, 4, 0); actual != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", actual, expected)
+	}
+	// Too wide for 30 columns, zfs and docker are stacked
+	expected = "Distro : Arch\n" +
+		"Kernel : 6.9\n" +
+		"ZFS    : \033[1;33mWarning\033[0m\n" +
+		" tank  : \033[1;33m80%\033[0m\n" +
+		" backup: 10%\n" +
+		"Docker : OK\n" +
+		" 日本: up\n" +
+		"  Manual: OK\n"
+	if actual := Layout(nil, blocks, colDef, 4, 30); actual != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", actual, expected)
+	}
+	expected = "Distro : Arch         ZFS : \033[1;33mWarning\033[0m\n" +
+		"Kernel : 6.9            tank  : \033[1;33m80%\033[0m\n" +
+		"                        backup: 10%\n"
+	colDef = [][]datasources.Column{{{Name: "info", Width: 16}, {Name: "zfs", Width: 17, Align: datasources.AlignRight}}}
+	if actual := Layout(nil, blocks, colDef, 2, 0); actual != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
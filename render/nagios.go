@@ -0,0 +1,119 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// Nagios plugin exit codes
+const (
+	CheckOK       = 0
+	CheckWarning  = 1
+	CheckCritical = 2
+	CheckUnknown  = 3
+)
+
+var checkNames = map[int]string{
+	CheckOK:       "OK",
+	CheckWarning:  "WARNING",
+	CheckCritical: "CRITICAL",
+	CheckUnknown:  "UNKNOWN",
+}
+
+// CheckCode returns the Nagios exit code for status
+func CheckCode(status datasources.Status) int {
+	switch status {
+	case datasources.StatusWarning:
+		return CheckWarning
+	case datasources.StatusCritical:
+		return CheckCritical
+	case datasources.StatusUnknown, datasources.StatusUnavailable:
+		return CheckUnknown
+	}
+	return CheckOK
+}
+
+// describeProblem returns the module name and status text followed by the names of its items which are not OK
+func describeProblem(name string, sr *datasources.SourceReturn) string {
+	problem := fmt.Sprintf("%s: %s", name, StatusText(sr))
+	var names []string
+	for _, it := range sr.Items {
+		if it.Status.Worse(datasources.StatusOK) && it.Name != "" {
+			names = append(names, it.Name)
+		}
+	}
+	if len(names) > 0 {
+		problem += fmt.Sprintf(" (%s)", strings.Join(names, ", "))
+	}
+	return problem
+}
+
+// Check renders the results of modules in order as a single line in the Nagios plugin format
+// and returns it with the exit code matching the worst module status.
+//
+// Modules which are not OK are listed with the names of their items which are not OK,
+// all module and item metrics are added as performance data.
+func Check(order []string, results map[string]datasources.SourceReturn) (string, int) {
+	worst := datasources.StatusOK
+	var problems []string
+	var perfData []string
+	var numModules int
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok {
+			continue
+		}
+		numModules++
+		if sr.Status.Worse(worst) {
+			worst = sr.Status
+		}
+		if sr.Status.Worse(datasources.StatusOK) {
+			// | separates the plugin output from the performance data
+			problems = append(problems, strings.ReplaceAll(describeProblem(k, &sr), "|", "/"))
+		}
+		for _, m := range sr.Metrics {
+			perfData = append(perfData, formatPerfData(k+" "+m.Name, &m))
+		}
+		for _, it := range sr.Items {
+			for _, m := range it.Metrics {
+				perfData = append(perfData, formatPerfData(k+" "+it.Name+" "+m.Name, &m))
+			}
+		}
+	}
+	code := CheckCode(worst)
+	var sb strings.Builder
+	sb.WriteString(checkNames[code])
+	sb.WriteString(" - ")
+	if len(problems) > 0 {
+		sb.WriteString(strings.Join(problems, ", "))
+	} else {
+		_, _ = fmt.Fprintf(&sb, "%d modules OK", numModules)
+	}
+	if len(perfData) > 0 {
+		sb.WriteString(" | ")
+		sb.WriteString(strings.Join(perfData, " "))
+	}
+	return sb.String(), code
+}
+
+// formatPerfData returns 'label'=value[UOM];[warn];[crit];[min];[max]
+func formatPerfData(label string, m *datasources.Metric) string {
+	f := func(v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	}
+	var min string
+	if m.Max != 0 {
+		min = "0"
+	}
+	label = strings.NewReplacer("=", "_", "|", "_", "'", "''").Replace(label)
+	s := fmt.Sprintf("'%s'=%s%s;%s;%s;%s;%s", label, strconv.FormatFloat(math.Round(m.Value*100)/100, 'f', -1, 64), m.Unit,
+		f(m.Warn), f(m.Crit), min, f(m.Max))
+	return strings.TrimRight(s, ";")
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestCheck(t *testing.T) {
+	results := map[string]datasources.SourceReturn{
+		"sysinfo": {Status: datasources.StatusInfo},
+		"zfs": {
+			Title:  "ZFS",
+			Status: datasources.StatusWarning,
+			Items: []datasources.Item{{
+				Name:    "tank",
+				Status:  datasources.StatusWarning,
+				Metrics: []datasources.Metric{{Name: "used_percent", Value: 75.123, Unit: "%", Warn: 70, Crit: 90, Max: 100}},
+			}},
+		},
+		"cpu": {
+			Title:  "CPU temp",
+			Status: datasources.StatusOK,
+			Items: []datasources.Item{{
+				Name:    "Core 0",
+				Status:  datasources.StatusOK,
+				Metrics: []datasources.Metric{{Name: "temperature_celsius", Value: 40, Warn: 70, Crit: 90}},
+			}},
+		},
+	}
+	line, code := Check([]string{"sysinfo", "zfs", "cpu"}, results)
+	expected := "WARNING - zfs: Warning (tank) | 'zfs tank used_percent'=75.12%;70;90;0;100 'cpu Core 0 temperature_celsius'=40;70;90"
+	if line != expected || code != CheckWarning {
+		t.Errorf("got %q (%d), expected %q (%d)", line, code, expected, CheckWarning)
+	}
+	results["docker"] = datasources.SourceReturn{Status: datasources.StatusCritical, Message: "a|b", Items: []datasources.Item{{
+		Name:    "web|db",
+		Status:  datasources.StatusCritical,
+		Metrics: []datasources.Metric{{Name: "running", Value: 0}},
+	}}}
+	line, _ = Check([]string{"docker"}, results)
+	if expected := "CRITICAL - docker: a/b (web/db) | 'docker web_db running'=0"; line != expected {
+		t.Errorf("got %q, expected %q", line, expected)
+	}
+	line, code = Check([]string{"sysinfo"}, results)
+	if line != "OK - 1 modules OK" || code != CheckOK {
+		t.Errorf("got %q (%d), expected OK", line, code)
+	}
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// metricPrefix is prepended to all Prometheus metric names
+const metricPrefix = "go_motd_"
+
+// reInvalidMetric matches characters which are not allowed in Prometheus metric names
+var reInvalidMetric = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// promSample is a single sample of a metric family
+type promSample struct {
+	labels string
+	value  float64
+}
+
+// promFamily is a metric name with its help text and samples
+type promFamily struct {
+	help    string
+	samples []promSample
+}
+
+// promFamilies groups samples by metric name
+type promFamilies map[string]*promFamily
+
+func (f promFamilies) add(name string, help string, value float64, labels ...string) {
+	fam, ok := f[name]
+	if !ok {
+		fam = &promFamily{help: help}
+		f[name] = fam
+	}
+	var sb strings.Builder
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		_, _ = fmt.Fprintf(&sb, `%s="%s"`, labels[i], escapeLabel(labels[i+1]))
+	}
+	fam.samples = append(fam.samples, promSample{labels: sb.String(), value: value})
+}
+
+// escapeLabel escapes a label value according to the Prometheus text format
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Prometheus writes the results of modules in the Prometheus text exposition format, collected is the time they were gathered.
+//
+// Module and item statuses are exported with the status as a label, item metrics are exported
+// using their own name with the module and item as labels.
+func Prometheus(w io.Writer, order []string, results map[string]datasources.SourceReturn, collected time.Time) error {
+	f := make(promFamilies)
+	f.add(metricPrefix+"last_refresh_timestamp_seconds", "Time the modules were last run", float64(collected.UnixNano())/1e9)
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok {
+			continue
+		}
+		f.add(metricPrefix+"module_status", "Module status, the value is always 1", 1,
+			"module", k, "status", sr.Status.String())
+		f.add(metricPrefix+"module_duration_seconds", "Time taken to run the module", sr.Time.Seconds(), "module", k)
+		for _, m := range sr.Metrics {
+			f.add(metricPrefix+reInvalidMetric.ReplaceAllString(k+"_"+m.Name, "_"), "", m.Value, "module", k)
+		}
+		for _, it := range sr.Items {
+			if it.Name == "" {
+				continue
+			}
+			if it.Status != datasources.StatusInfo {
+				labels := []string{"module", k, "item", it.Name, "status", it.Status.String()}
+				// Items without measurements are states, for example a container or unit
+				if len(it.Metrics) == 0 {
+					labels = append(labels, "state", it.Value)
+				}
+				f.add(metricPrefix+"item_status", "Item status, the value is always 1", 1, labels...)
+			}
+			for _, m := range it.Metrics {
+				f.add(metricPrefix+reInvalidMetric.ReplaceAllString(m.Name, "_"), "", m.Value, "module", k, "item", it.Name)
+			}
+		}
+	}
+	names := make([]string, 0, len(f))
+	for k := range f {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fam := f[name]
+		if fam.help != "" {
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n", name, fam.help); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n", name); err != nil {
+			return err
+		}
+		for _, s := range fam.samples {
+			value := strconv.FormatFloat(s.value, 'g', -1, 64)
+			var err error
+			if s.labels == "" {
+				_, err = fmt.Fprintf(w, "%s %s\n", name, value)
+			} else {
+				_, err = fmt.Fprintf(w, "%s{%s} %s\n", name, s.labels, value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
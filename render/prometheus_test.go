@@ -0,0 +1,51 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestPrometheus(t *testing.T) {
+	results := map[string]datasources.SourceReturn{
+		"docker": {
+			Status: datasources.StatusWarning,
+			Time:   1500 * time.Millisecond,
+			Items:  []datasources.Item{{Name: `web"1`, Value: "exited", Status: datasources.StatusCritical}},
+		},
+		"zfs": {
+			Status: datasources.StatusOK,
+			Items: []datasources.Item{{
+				Name:    "tank",
+				Value:   "ONLINE, 1 TB used out of 2 TB",
+				Status:  datasources.StatusOK,
+				Metrics: []datasources.Metric{{Name: "used_bytes", Value: 1e12}},
+			}},
+		},
+		"updates": {
+			Status:  datasources.StatusInfo,
+			Metrics: []datasources.Metric{{Name: "pending", Value: 3}},
+		},
+	}
+	var sb strings.Builder
+	if err := Prometheus(&sb, []string{"docker", "zfs", "updates"}, results, time.Unix(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, line := range []string{
+		"go_motd_last_refresh_timestamp_seconds 10\n",
+		`go_motd_module_status{module="docker",status="warning"} 1` + "\n",
+		`go_motd_module_duration_seconds{module="docker"} 1.5` + "\n",
+		`go_motd_item_status{module="docker",item="web\"1",status="critical",state="exited"} 1` + "\n",
+		`go_motd_item_status{module="zfs",item="tank",status="ok"} 1` + "\n",
+		`go_motd_used_bytes{module="zfs",item="tank"} 1e+12` + "\n",
+		`go_motd_updates_pending{module="updates"} 3` + "\n",
+		"# TYPE go_motd_used_bytes gauge\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("missing %q in output:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"math"
+	"strings"
+)
+
+// defaultSparklineWidth is used if sparkline_width is not set
+const defaultSparklineWidth = 10
+
+// sparkBlocks are the Unicode blocks of a sparkline from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as Unicode blocks scaled between their minimum and maximum, the line is flat if they
+// differ by less than 0.1%.
+//
+// If there are more values than width, consecutive values are averaged so the sparkline is width cells wide.
+func Sparkline(values []float64, width int) string {
+	if width <= 0 {
+		width = defaultSparklineWidth
+	}
+	if len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start, end := i*len(values)/width, (i+1)*len(values)/width
+			var sum float64
+			for _, v := range values[start:end] {
+				sum += v
+			}
+			buckets[i] = sum / float64(end-start)
+		}
+		values = buckets
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	flat := hi-lo <= math.Max(math.Abs(hi), 1)*1e-3
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if !flat {
+			i = int(math.Round((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1)))
+		}
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package render
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values   []float64
+		width    int
+		expected string
+	}{
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, 0, "▁▂▃▄▅▆▇█"},
+		{[]float64{40, 40, 40.001}, 0, "▁▁▁"},
+		{[]float64{0, 2, 7, 7}, 2, "▁█"},
+	}
+	for _, tt := range tests {
+		if actual := Sparkline(tt.values, tt.width); actual != tt.expected {
+			t.Errorf("Sparkline(%v, %d) = %q, expected %q", tt.values, tt.width, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// Module returns the module called name, nil if it did not run
+func (d *Document) Module(name string) *Module {
+	for i := range d.Modules {
+		if d.Modules[i].Name == name {
+			return &d.Modules[i]
+		}
+	}
+	return nil
+}
+
+// Failing returns the items which are not OK or informational
+func Failing(items []datasources.Item) []datasources.Item {
+	var ret []datasources.Item
+	for _, it := range items {
+		if it.Status.Worse(datasources.StatusOK) {
+			ret = append(ret, it)
+		}
+	}
+	return ret
+}
+
+// templateFuncs returns the functions available in templates, text renders a module the same way as the text format
+func templateFuncs(text func(name string) string) template.FuncMap {
+	return template.FuncMap{
+		"color":   Colorize,
+		"failing": Failing,
+		"join":    strings.Join,
+		"text":    text,
+	}
+}
+
+// Template renders the results of modules in order using the text/template tmpl, the data is a Document
+//
+// The functions color (colorize a string according to a status), failing (filter items which are not OK),
+// join (strings.Join) and text (render a module by name like the text format) are available.
+func Template(tmpl string, order []string, results map[string]datasources.SourceReturn, text func(name string) string) ([]byte, error) {
+	t, err := template.New("output").Funcs(templateFuncs(text)).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, NewDocument(order, results)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+func TestTemplate(t *testing.T) {
+	defer func(noColors bool) { utils.NoColors = noColors }(utils.NoColors)
+	utils.NoColors = true
+	results := map[string]datasources.SourceReturn{
+		"zfs": {
+			Title:  "ZFS",
+			Status: datasources.StatusWarning,
+			Items: []datasources.Item{
+				{Name: "tank", Value: "80%", Status: datasources.StatusWarning},
+				{Name: "backup", Value: "10%", Status: datasources.StatusOK},
+			},
+		},
+		"sysinfo": {Status: datasources.StatusInfo, Items: []datasources.Item{{Name: "Distro", Value: "Arch", Status: datasources.StatusInfo}}},
+	}
+	tmpl := `{{.Status}}{{range .Modules}}{{range failing .Items}} {{.Name}}={{color .Status .Value}}{{end}}{{end}}` +
+		`{{with .Module "sysinfo"}} {{text .Name}}{{end}}`
+	text := func(name string) string { return "<" + name + ">" }
+	out, err := Template(tmpl, []string{"sysinfo", "zfs"}, results, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "warning tank=80% <sysinfo>"; string(out) != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// statusLabels are shown in the header when the module has no message
+var statusLabels = map[datasources.Status]string{
+	datasources.StatusInfo:        "Info",
+	datasources.StatusOK:          "OK",
+	datasources.StatusUnavailable: "Unavailable",
+	datasources.StatusUnknown:     "Unknown",
+	datasources.StatusWarning:     "Warning",
+	datasources.StatusCritical:    "Critical",
+}
+
+// statusStyle returns the style of the current theme for status
+func statusStyle(status datasources.Status) utils.Style {
+	t := utils.CurrentTheme()
+	switch status {
+	case datasources.StatusInfo:
+		return t.Info
+	case datasources.StatusOK:
+		return t.Good
+	case datasources.StatusUnavailable:
+		return t.Muted
+	case datasources.StatusUnknown, datasources.StatusWarning:
+		return t.Warn
+	case datasources.StatusCritical:
+		return t.Err
+	}
+	return utils.Style{}
+}
+
+// Colorize colors s according to status using the current theme
+func Colorize(status datasources.Status, s string) string {
+	return statusStyle(status).Sprint(s)
+}
+
+// StatusText returns the header text of sr, its message or the name of its status
+func StatusText(sr *datasources.SourceReturn) string {
+	if sr.Message != "" {
+		return sr.Message
+	}
+	return statusLabels[sr.Status]
+}
+
+// TextBlock renders a module result as a block to be laid out, c is used for padding, usage bars and sparklines and OK items are hidden if warnOnly is true
+func TextBlock(sr *datasources.SourceReturn, c *datasources.ConfBase, warnOnly bool) *Block {
+	b := Block{PadHeader: c.PadHeader, PadContent: c.PadContent}
+	lines := &b.Content
+	if sr.Title != "" {
+		value := Colorize(sr.Status, StatusText(sr))
+		if c.Sparkline && len(sr.Trend) > 1 {
+			value += " " + Sparkline(sr.Trend, c.SparklineWidth)
+		}
+		b.Header = append(b.Header, Line{utils.Label(sr.Title), value})
+	} else {
+		// Items are the header if there is no title
+		lines = &b.Header
+	}
+	for _, it := range sr.Items {
+		if warnOnly && it.Status == datasources.StatusOK {
+			continue
+		}
+		value := Colorize(it.Status, it.Value+it.Unit)
+		if m, ok := it.Usage(); ok && c.Bar {
+			value = Bar(m, c.BarWidth, c.BarASCII) + " " + value
+		}
+		if c.Sparkline && len(it.Trend) > 1 {
+			value += " " + Sparkline(it.Trend, c.SparklineWidth)
+		}
+		if it.Name == "" {
+			*lines = append(*lines, Line{Value: value})
+		} else {
+			*lines = append(*lines, Line{utils.Label(it.Name), value})
+		}
+	}
+	return &b
+}
+
+// Text renders a module result on its own, c is used for padding and OK items are hidden if warnOnly is true
+func Text(sr *datasources.SourceReturn, c *datasources.ConfBase, warnOnly bool) string {
+	return TextBlock(sr, c, warnOnly).String()
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+func TestText(t *testing.T) {
+	defer func(noColors bool) { utils.NoColors = noColors }(utils.NoColors)
+	utils.NoColors = true
+	var c datasources.ConfBase
+	c.Init()
+	c.PadHeader = []int{0, 1}
+	sr := datasources.SourceReturn{
+		Title:  "Test",
+		Status: datasources.StatusWarning,
+		Items: []datasources.Item{
+			{Name: "good", Value: "20", Unit: "°C", Status: datasources.StatusOK},
+			{Name: "hot", Value: "80", Unit: "°C", Status: datasources.StatusWarning},
+		},
+	}
+	expected := map[bool]string{
+		false: "Test : Warning\n good: 20°C\n hot : 80°C",
+		true:  "Test : Warning\n hot: 80°C",
+	}
+	for warnOnly, v := range expected {
+		if actual := Text(&sr, &c, warnOnly); actual != v {
+			t.Errorf("warnOnly %v: got %q, expected %q", warnOnly, actual, v)
+		}
+	}
+}
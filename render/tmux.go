@@ -0,0 +1,40 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// tmuxStatus are the short status names used in the tmux format
+var tmuxStatus = map[datasources.Status]string{
+	datasources.StatusOK:          "ok",
+	datasources.StatusUnavailable: "n/a",
+	datasources.StatusUnknown:     "unknown",
+	datasources.StatusWarning:     "warn",
+	datasources.StatusCritical:    "crit",
+}
+
+// tmuxColorize wraps s in tmux style codes for status using the current theme, unless colors are disabled
+func tmuxColorize(status datasources.Status, s string) string {
+	style := statusStyle(status).Tmux()
+	if utils.NoColors || style == "" {
+		return s
+	}
+	return "#[" + style + "]" + s + "#[default]"
+}
+
+// Tmux renders the results of modules in order as a single line for the tmux status line,
+// one name:status token per module. Informational modules such as sysinfo are skipped.
+func Tmux(order []string, results map[string]datasources.SourceReturn) []byte {
+	var tokens []string
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok || sr.Status == datasources.StatusInfo {
+			continue
+		}
+		tokens = append(tokens, tmuxColorize(sr.Status, k+":"+tmuxStatus[sr.Status]))
+	}
+	return []byte(strings.Join(tokens, " ") + "\n")
+}
@@ -0,0 +1,23 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/utils"
+)
+
+func TestTmux(t *testing.T) {
+	defer func(noColors bool) { utils.NoColors = noColors }(utils.NoColors)
+	utils.NoColors = false
+	results := map[string]datasources.SourceReturn{
+		"sysinfo": {Status: datasources.StatusInfo},
+		"docker":  {Status: datasources.StatusOK},
+		"zfs":     {Status: datasources.StatusWarning},
+		"btrfs":   {Status: datasources.StatusUnavailable},
+	}
+	expected := "#[fg=green,bold]docker:ok#[default] #[fg=yellow,bold]zfs:warn#[default] #[fg=yellow,bold]btrfs:n/a#[default]\n"
+	if actual := string(Tmux([]string{"sysinfo", "docker", "zfs", "btrfs"}, results)); actual != expected {
+		t.Errorf("got %q, expected %q", actual, expected)
+	}
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// Transition is a change of the status of a module or one of its items between two runs
+type Transition struct {
+	// Module name
+	Module string `json:"module"`
+	// Item name, empty if the status of the module changed
+	Item string `json:"item,omitempty"`
+	// Status before the change, OK for new items
+	Old datasources.Status `json:"old_status"`
+	// Status after the change, OK for removed items
+	New datasources.Status `json:"new_status"`
+	// Value of the item or message of the module after the change
+	Value string `json:"value,omitempty"`
+}
+
+// Key identifies the module or item which changed, module or module/item
+func (t Transition) Key() string {
+	if t.Item == "" {
+		return t.Module
+	}
+	return t.Module + "/" + t.Item
+}
+
+// String describes the transition, for example "systemd nginx.service: ok → critical (failed)"
+func (t Transition) String() string {
+	name := t.Module
+	if t.Item != "" {
+		name += " " + t.Item
+	}
+	s := fmt.Sprintf("%s: %s → %s", name, t.Old, t.New)
+	if t.Value != "" {
+		s += " (" + t.Value + ")"
+	}
+	return s
+}
+
+// Transitions compares the status of modules in order with the previous document, modules which were not in it
+// and informational modules are skipped.
+//
+// Named items with a changed status, new items which are not OK and removed items which were not OK are reported,
+// the module status is reported if none of its items changed. Items are only compared if the module returned its data
+// in both runs, otherwise only the change of the module status is reported.
+func Transitions(prev *Document, order []string, results map[string]datasources.SourceReturn) []Transition {
+	if prev == nil {
+		return nil
+	}
+	var transitions []Transition
+	for _, k := range order {
+		sr, ok := results[k]
+		pm := prev.Module(k)
+		if !ok || pm == nil || sr.Status == datasources.StatusInfo {
+			continue
+		}
+		var moduleTransitions []Transition
+		if compareItems(pm, &sr) {
+			moduleTransitions = itemTransitions(k, diffItems(pm.Items, sr.Items))
+		}
+		if len(moduleTransitions) == 0 && sr.Status != pm.Status {
+			moduleTransitions = append(moduleTransitions, Transition{Module: k, Old: pm.Status, New: sr.Status, Value: sr.Message})
+		}
+		transitions = append(transitions, moduleTransitions...)
+	}
+	return transitions
+}
+
+// itemTransitions returns the status transitions of the item differences of module, new items are treated as
+// previously OK and removed items as OK now, they are only reported if they are not OK
+func itemTransitions(module string, diffs []itemDiff) []Transition {
+	var transitions []Transition
+	for _, d := range diffs {
+		switch {
+		case d.cur == nil:
+			if d.old.Status.Worse(datasources.StatusOK) {
+				transitions = append(transitions, Transition{module, d.old.Name, d.old.Status, datasources.StatusOK, "removed"})
+			}
+		case d.old == nil:
+			if d.cur.Status.Worse(datasources.StatusOK) {
+				transitions = append(transitions, Transition{module, d.cur.Name, datasources.StatusOK, d.cur.Status, d.cur.Value + d.cur.Unit})
+			}
+		default:
+			transitions = append(transitions, Transition{module, d.cur.Name, d.old.Status, d.cur.Status, d.cur.Value + d.cur.Unit})
+		}
+	}
+	return transitions
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestTransitions(t *testing.T) {
+	prev := &Document{Modules: []Module{
+		{Name: "zfs", Status: datasources.StatusOK, Items: []datasources.Item{
+			{Name: "tank", Value: "ONLINE", Status: datasources.StatusOK},
+			{Name: "backup", Value: "FAULTED", Status: datasources.StatusCritical},
+		}},
+		{Name: "updates", Status: datasources.StatusOK},
+		{Name: "systemd", Status: datasources.StatusCritical, Items: []datasources.Item{
+			{Name: "nginx.service", Value: "failed", Status: datasources.StatusCritical},
+		}},
+	}}
+	results := map[string]datasources.SourceReturn{
+		"zfs": {Status: datasources.StatusWarning, Items: []datasources.Item{
+			{Name: "tank", Value: "DEGRADED", Status: datasources.StatusWarning},
+			{Name: "scratch", Value: "ONLINE", Status: datasources.StatusOK},
+		}},
+		"updates": {Status: datasources.StatusWarning, Message: "12 pending"},
+		"systemd": {Status: datasources.StatusUnknown, Message: "timed out", Error: context.DeadlineExceeded},
+	}
+	expected := []Transition{
+		{"zfs", "tank", datasources.StatusOK, datasources.StatusWarning, "DEGRADED"},
+		{"zfs", "backup", datasources.StatusCritical, datasources.StatusOK, "removed"},
+		{"updates", "", datasources.StatusOK, datasources.StatusWarning, "12 pending"},
+		{"systemd", "", datasources.StatusCritical, datasources.StatusUnknown, "timed out"},
+	}
+	actual := Transitions(prev, []string{"zfs", "updates", "systemd"}, results)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got:\n%v\nexpected:\n%v", actual, expected)
+	}
+	if s := actual[0].String(); s != "zfs tank: ok → warning (DEGRADED)" {
+		t.Errorf("got %q", s)
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+// WaybarBlock is the JSON object read by waybar custom modules with return-type json
+type WaybarBlock struct {
+	// Text shown in the bar, OK or the modules which are not OK
+	Text string `json:"text"`
+	// Tooltip with the full text output, Pango markup characters are escaped
+	Tooltip string `json:"tooltip"`
+	// Class is the name of the worst status, for example warning, it can be used in the waybar stylesheet
+	Class string `json:"class"`
+	// Percentage is the highest usage of all items, it can be used to select format-icons
+	Percentage int `json:"percentage"`
+}
+
+// pangoEscaper escapes the characters which are interpreted by Pango markup
+var pangoEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Waybar renders the results of modules in order as a single line JSON object for waybar custom modules,
+// tooltip is the full text output.
+//
+// The text lists the modules which are not OK like the check mode, or OK if there are none.
+func Waybar(order []string, results map[string]datasources.SourceReturn, tooltip string) ([]byte, error) {
+	worst := datasources.StatusOK
+	var problems []string
+	var percent float64
+	for _, k := range order {
+		sr, ok := results[k]
+		if !ok {
+			continue
+		}
+		if sr.Status.Worse(worst) {
+			worst = sr.Status
+		}
+		if sr.Status.Worse(datasources.StatusOK) {
+			problems = append(problems, describeProblem(k, &sr))
+		}
+		for _, it := range sr.Items {
+			if m, ok := it.Usage(); ok {
+				percent = math.Max(percent, m.Value/m.Max*100)
+			}
+		}
+	}
+	block := WaybarBlock{
+		Text:       statusLabels[datasources.StatusOK],
+		Tooltip:    pangoEscaper.Replace(strings.TrimRight(tooltip, "\n")),
+		Class:      worst.String(),
+		Percentage: int(math.Round(math.Min(percent, 100))),
+	}
+	if len(problems) > 0 {
+		block.Text = pangoEscaper.Replace(strings.Join(problems, ", "))
+	}
+	// Encode adds the newline which ends the object
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(block); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/cosandr/go-motd/datasources"
+)
+
+func TestWaybar(t *testing.T) {
+	results := map[string]datasources.SourceReturn{
+		"sysinfo": {Status: datasources.StatusInfo},
+		"zfs": {Title: "ZFS", Status: datasources.StatusWarning, Items: []datasources.Item{
+			{Name: "tank<&>", Value: "80%", Status: datasources.StatusWarning, Metrics: []datasources.Metric{
+				{Name: "used_percent", Value: 80.4, Unit: "%", Max: 100},
+			}},
+			{Name: "backup", Value: "10%", Status: datasources.StatusOK},
+		}},
+		"docker": {Title: "Docker", Status: datasources.StatusOK},
+	}
+	out, err := Waybar([]string{"sysinfo", "zfs", "docker"}, results, "ZFS: Warning\n tank: 80% <&>\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"text":"zfs: Warning (tank&lt;&amp;&gt;)","tooltip":"ZFS: Warning\n tank: 80% &lt;&amp;&gt;","class":"warning","percentage":80}` + "\n"
+	if string(out) != expected {
+		t.Errorf("got %s, expected %s", out, expected)
+	}
+	out, _ = Waybar([]string{"sysinfo", "docker"}, results, "")
+	expected = `{"text":"OK","tooltip":"","class":"ok","percentage":0}` + "\n"
+	if string(out) != expected {
+		t.Errorf("got %s, expected %s", out, expected)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cosandr/go-motd/datasources"
+	"github.com/cosandr/go-motd/render"
+	"github.com/cosandr/go-motd/utils"
+)
+
+// systemStateFile keeps the previous results in daemon mode
+const systemStateFile = "/var/lib/go-motd/state.json"
+
+// stateFilePath returns the state file set in the config, otherwise the system state file in daemon mode
+// or go-motd/state.json in $XDG_STATE_HOME (~/.local/state by default)
+func stateFilePath(c *datasources.Conf) string {
+	if c.StateFile != "" {
+		return c.StateFile
+	}
+	if args.Daemon {
+		return systemStateFile
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "go-motd", "state.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "go-motd", "state.json")
+}
+
+// readState returns the results saved in path, nil if it does not exist
+func readState(path string) (*render.Document, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var doc render.Document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// writeState saves doc to path, modules from prev which did not run are kept
+func writeState(path string, doc *render.Document, prev *render.Document) error {
+	if prev != nil {
+		for _, m := range prev.Modules {
+			if doc.Module(m.Name) == nil {
+				doc.Modules = append(doc.Modules, m)
+			}
+		}
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, b, 0644, -1, -1)
+}
+
+// loadTrends returns the trends kept in daemon mode, prev resized if it is not nil, or nil if they are disabled.
+// Samples saved in trend_file are read if it is set.
+func loadTrends(c *datasources.Conf, prev *datasources.Trends) *datasources.Trends {
+	if c.TrendSamples == 0 {
+		return nil
+	}
+	if prev != nil {
+		prev.Resize(c.TrendSamples)
+		return prev
+	}
+	t := datasources.NewTrends(c.TrendSamples)
+	if c.TrendFile == "" {
+		return t
+	}
+	b, err := os.ReadFile(c.TrendFile)
+	if err == nil {
+		err = json.Unmarshal(b, t)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("cannot read trends: %v", err)
+	}
+	return t
+}
+
+// saveTrends writes the samples to trend_file if it is set
+func saveTrends(c *datasources.Conf, t *datasources.Trends) {
+	if t == nil || c.TrendFile == "" {
+		return
+	}
+	b, err := json.Marshal(t)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.TrendFile), 0755)
+	}
+	if err == nil {
+		err = utils.WriteFileAtomic(c.TrendFile, b, 0644, -1, -1)
+	}
+	if err != nil {
+		log.Warnf("cannot save trends: %v", err)
+	}
+}
+
+// historyChanges compares the results with the previous ones in the state file and saves them,
+// the changes are returned as a result to show before all other modules or nil if nothing changed.
+func historyChanges(c *datasources.Conf, order []string, results map[string]datasources.SourceReturn) *datasources.SourceReturn {
+	path := stateFilePath(c)
+	if path == "" {
+		log.Warn("history: cannot determine state file")
+		return nil
+	}
+	prev, err := readState(path)
+	if err != nil {
+		log.Warnf("cannot read state: %v", err)
+	}
+	if err := writeState(path, render.NewDocument(order, results), prev); err != nil {
+		log.Warnf("cannot write state: %v", err)
+	}
+	changes := render.Changes(prev, order, results)
+	if len(changes) == 0 {
+		return nil
+	}
+	sr := render.ChangesResult(prev, changes)
+	return &sr
+}
+
+// withChanges returns copies of order and results with changes first, they are returned as is if changes is nil.
+// Changes are only shown in text and HTML, they are not a module.
+func withChanges(order []string, results map[string]datasources.SourceReturn, changes *datasources.SourceReturn) ([]string, map[string]datasources.SourceReturn) {
+	if changes == nil {
+		return order, results
+	}
+	merged := make(map[string]datasources.SourceReturn, len(results)+1)
+	for k, v := range results {
+		merged[k] = v
+	}
+	merged[datasources.ChangesKey] = *changes
+	return append([]string{datasources.ChangesKey}, order...), merged
+}
@@ -1,32 +1,312 @@
 package utils
 
-import "fmt"
-
-var (
-	Good     = green
-	Warn     = yellow
-	Err      = red
-	NoColors = false
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-var (
-	//black   = Color("\033[1;30m%s\033[0m")
-	red    = Color("\033[1;31m%s\033[0m")
-	green  = Color("\033[1;32m%s\033[0m")
-	yellow = Color("\033[1;33m%s\033[0m")
-	//purple  = Color("\033[1;34m%s\033[0m")
-	//magenta = Color("\033[1;35m%s\033[0m")
-	//teal    = Color("\033[1;36m%s\033[0m")
-	//white   = Color("\033[1;37m%s\033[0m")
-)
+var NoColors = false
+
+// Style is a parsed color specification, the zero value does not change the text
+type Style struct {
+	// SGR parameters, for example 1;38;5;214
+	sgr string
+}
+
+// namedColors are the foreground colors of the basic 16 color palette
+var namedColors = map[string]int{
+	"black":          30,
+	"red":            31,
+	"green":          32,
+	"yellow":         33,
+	"blue":           34,
+	"magenta":        35,
+	"cyan":           36,
+	"white":          37,
+	"gray":           90,
+	"grey":           90,
+	"bright-black":   90,
+	"bright-red":     91,
+	"bright-green":   92,
+	"bright-yellow":  93,
+	"bright-blue":    94,
+	"bright-magenta": 95,
+	"bright-cyan":    96,
+	"bright-white":   97,
+}
+
+// attributes are the supported text attributes
+var attributes = map[string]int{
+	"bold":      1,
+	"dim":       2,
+	"italic":    3,
+	"underline": 4,
+}
+
+// ParseStyle parses a space separated list of attributes and a color.
+//
+// The color is a name such as red or bright-blue, a 256 color palette index or a #rrggbb hex value.
+// Attributes are bold, dim, italic and underline. An empty string or none does not change the text.
+func ParseStyle(spec string) (Style, error) {
+	var params []string
+	var color string
+	for _, tok := range strings.Fields(strings.ToLower(spec)) {
+		if tok == "none" || tok == "default" {
+			continue
+		}
+		if a, ok := attributes[tok]; ok {
+			params = append(params, strconv.Itoa(a))
+			continue
+		}
+		if color != "" {
+			return Style{}, fmt.Errorf("more than one color in %q", spec)
+		}
+		if c, ok := namedColors[tok]; ok {
+			color = strconv.Itoa(c)
+		} else if strings.HasPrefix(tok, "#") {
+			rgb, err := strconv.ParseUint(tok[1:], 16, 32)
+			if err != nil || len(tok) != 7 {
+				return Style{}, fmt.Errorf("invalid hex color %s", tok)
+			}
+			color = fmt.Sprintf("38;2;%d;%d;%d", rgb>>16, (rgb>>8)&0xff, rgb&0xff)
+		} else if n, err := strconv.ParseUint(tok, 10, 8); err == nil {
+			color = fmt.Sprintf("38;5;%d", n)
+		} else {
+			return Style{}, fmt.Errorf("unknown color or attribute %s", tok)
+		}
+	}
+	if color != "" {
+		params = append(params, color)
+	}
+	return Style{sgr: strings.Join(params, ";")}, nil
+}
+
+// MustParseStyle is like ParseStyle but panics if spec is invalid
+func MustParseStyle(spec string) Style {
+	s, err := ParseStyle(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Sprint formats args like fmt.Sprint and applies the style unless colors are disabled
+func (s Style) Sprint(args ...interface{}) string {
+	if NoColors || s.sgr == "" {
+		return fmt.Sprint(args...)
+	}
+	return "\033[" + s.sgr + "m" + fmt.Sprint(args...) + "\033[0m"
+}
+
+// basicPalette are the RGB values of the 16 basic colors, as used by xterm
+var basicPalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// paletteHex returns the RGB value of a 256 color palette index
+func paletteHex(n int) string {
+	if n < 16 {
+		return basicPalette[n]
+	}
+	if n >= 232 {
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	n -= 16
+	return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+}
+
+// sgrAttributes are the decoded SGR parameters of a style
+type sgrAttributes struct {
+	// Attribute codes, 1 to 4
+	attrs []int
+	// Color index in the 256 color palette, -1 if not set or rgb is used
+	color int
+	// True color as #rrggbb
+	rgb string
+}
+
+// decode parses the SGR parameters of the style
+func (s Style) decode() sgrAttributes {
+	a := sgrAttributes{color: -1}
+	if s.sgr == "" {
+		return a
+	}
+	params := strings.Split(s.sgr, ";")
+	for i := 0; i < len(params); i++ {
+		n, _ := strconv.Atoi(params[i])
+		switch {
+		case n >= 1 && n <= 4:
+			a.attrs = append(a.attrs, n)
+		case n >= 30 && n <= 37:
+			a.color = n - 30
+		case n >= 90 && n <= 97:
+			a.color = n - 90 + 8
+		case n == 38 && i+2 < len(params) && params[i+1] == "5":
+			a.color, _ = strconv.Atoi(params[i+2])
+			i += 2
+		case n == 38 && i+4 < len(params) && params[i+1] == "2":
+			var rgb [3]int
+			for j := range rgb {
+				rgb[j], _ = strconv.Atoi(params[i+2+j])
+			}
+			a.rgb = fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+			i += 4
+		}
+	}
+	return a
+}
+
+// cssAttributes are the CSS declarations of the attribute codes
+var cssAttributes = map[int]string{1: "font-weight: bold", 2: "opacity: 0.7", 3: "font-style: italic", 4: "text-decoration: underline"}
+
+// CSS returns the style as CSS declarations, for example "font-weight: bold; color: #cd0000"
+func (s Style) CSS() string {
+	a := s.decode()
+	var decls []string
+	for _, n := range a.attrs {
+		decls = append(decls, cssAttributes[n])
+	}
+	if a.rgb != "" {
+		decls = append(decls, "color: "+a.rgb)
+	} else if a.color >= 0 {
+		decls = append(decls, "color: "+paletteHex(a.color))
+	}
+	return strings.Join(decls, "; ")
+}
+
+// tmuxAttributes are the tmux names of the attribute codes
+var tmuxAttributes = map[int]string{1: "bold", 2: "dim", 3: "italics", 4: "underscore"}
+
+// tmuxColors are the tmux names of the 8 basic colors, the bright ones are prefixed with bright
+var tmuxColors = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
 
-func Color(colorString string) func(...interface{}) string {
-	sprint := func(args ...interface{}) string {
-		if NoColors {
-			return fmt.Sprint(args...)
+// Tmux returns the style as a tmux style, for example "fg=red,bold"
+func (s Style) Tmux() string {
+	a := s.decode()
+	var opts []string
+	if a.rgb != "" {
+		opts = append(opts, "fg="+a.rgb)
+	} else if a.color >= 8 && a.color < 16 {
+		opts = append(opts, "fg=bright"+tmuxColors[a.color-8])
+	} else if a.color >= 0 && a.color < 8 {
+		opts = append(opts, "fg="+tmuxColors[a.color])
+	} else if a.color >= 16 {
+		opts = append(opts, fmt.Sprintf("fg=colour%d", a.color))
+	}
+	for _, n := range a.attrs {
+		opts = append(opts, tmuxAttributes[n])
+	}
+	return strings.Join(opts, ",")
+}
+
+// Theme maps severities to styles
+type Theme struct {
+	// Good is used for OK values
+	Good Style
+	// Warn is used for warnings and unknown values
+	Warn Style
+	// Err is used for critical values
+	Err Style
+	// Info is used for informational values
+	Info Style
+	// Label is used for titles and item names
+	Label Style
+	// Muted is used for unavailable modules
+	Muted Style
+}
+
+// ThemeSpec is a theme before parsing, each field is a style specification accepted by ParseStyle
+type ThemeSpec struct {
+	Good, Warn, Err, Info, Label, Muted string
+}
+
+// Parse parses every style of the theme
+func (t ThemeSpec) Parse() (theme Theme, err error) {
+	for _, f := range []struct {
+		spec  string
+		style *Style
+	}{
+		{t.Good, &theme.Good},
+		{t.Warn, &theme.Warn},
+		{t.Err, &theme.Err},
+		{t.Info, &theme.Info},
+		{t.Label, &theme.Label},
+		{t.Muted, &theme.Muted},
+	} {
+		if *f.style, err = ParseStyle(f.spec); err != nil {
+			return
 		}
-		return fmt.Sprintf(colorString,
-			fmt.Sprint(args...))
 	}
-	return sprint
+	return
+}
+
+// ThemePresets are the built-in themes
+var ThemePresets = map[string]ThemeSpec{
+	"default": {Good: "bold green", Warn: "bold yellow", Err: "bold red", Muted: "bold yellow"},
+	"solarized": {
+		Good:  "#859900",
+		Warn:  "#b58900",
+		Err:   "bold #dc322f",
+		Info:  "#268bd2",
+		Label: "#586e75",
+		Muted: "#93a1a1",
+	},
+	"high-contrast": {Good: "bold 28", Warn: "bold 130", Err: "bold 160", Info: "18", Label: "bold", Muted: "dim"},
+}
+
+// PresetNames returns the names of the built-in themes sorted
+func PresetNames() []string {
+	names := make([]string, 0, len(ThemePresets))
+	for k := range ThemePresets {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var theme = DefaultTheme()
+
+// DefaultTheme returns the default preset, bold green, yellow and red
+func DefaultTheme() Theme {
+	t, _ := ThemePresets["default"].Parse()
+	return t
+}
+
+// SetTheme changes the theme used by Good, Warn, Err, Info, Label and Muted
+func SetTheme(t Theme) {
+	theme = t
+}
+
+// CurrentTheme returns the theme set by SetTheme
+func CurrentTheme() Theme {
+	return theme
+}
+
+func Good(args ...interface{}) string {
+	return theme.Good.Sprint(args...)
+}
+
+func Warn(args ...interface{}) string {
+	return theme.Warn.Sprint(args...)
+}
+
+func Err(args ...interface{}) string {
+	return theme.Err.Sprint(args...)
+}
+
+func Info(args ...interface{}) string {
+	return theme.Info.Sprint(args...)
+}
+
+func Label(args ...interface{}) string {
+	return theme.Label.Sprint(args...)
+}
+
+func Muted(args ...interface{}) string {
+	return theme.Muted.Sprint(args...)
 }
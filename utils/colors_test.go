@@ -0,0 +1,65 @@
+package utils
+
+import "testing"
+
+func TestParseStyle(t *testing.T) {
+	expected := map[string]string{
+		"":             "",
+		"none":         "",
+		"bold red":     "1;31",
+		"Red BOLD":     "1;31",
+		"214":          "38;5;214",
+		"dim #dc322f":  "2;38;2;220;50;47",
+		"bright-green": "92",
+	}
+	for spec, sgr := range expected {
+		s, err := ParseStyle(spec)
+		if err != nil {
+			t.Errorf("%q: %v", spec, err)
+		} else if s.sgr != sgr {
+			t.Errorf("%q: got %q, expected %q", spec, s.sgr, sgr)
+		}
+	}
+	for _, spec := range []string{"red green", "256", "#12345", "blink"} {
+		if _, err := ParseStyle(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+	for name, spec := range ThemePresets {
+		if _, err := spec.Parse(); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+}
+
+func TestStyleCSS(t *testing.T) {
+	expected := map[string]string{
+		"":               "",
+		"bold red":       "font-weight: bold; color: #cd0000",
+		"dim 214":        "opacity: 0.7; color: #ffaf00",
+		"244":            "color: #808080",
+		"italic #268bd2": "font-style: italic; color: #268bd2",
+		"bright-blue":    "color: #5c5cff",
+	}
+	for spec, css := range expected {
+		if actual := MustParseStyle(spec).CSS(); actual != css {
+			t.Errorf("%q: got %q, expected %q", spec, actual, css)
+		}
+	}
+}
+
+func TestStyleTmux(t *testing.T) {
+	expected := map[string]string{
+		"":             "",
+		"bold red":     "fg=red,bold",
+		"dim 214":      "fg=colour214,dim",
+		"bright-green": "fg=brightgreen",
+		"#268bd2":      "fg=#268bd2",
+		"underline":    "underscore",
+	}
+	for spec, tmux := range expected {
+		if actual := MustParseStyle(spec).Tmux(); actual != tmux {
+			t.Errorf("%q: got %q, expected %q", spec, actual, tmux)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "motd")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+	if err := WriteFileAtomic(path, []byte("new"), 0600, uid, gid); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "new" {
+		t.Errorf("got %q %v, expected new", b, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, expected 0600", info.Mode().Perm())
+	}
+	if st := info.Sys().(*syscall.Stat_t); int(st.Uid) != uid || int(st.Gid) != gid {
+		t.Errorf("got owner %d:%d, expected %d:%d", st.Uid, st.Gid, uid, gid)
+	}
+	// The temporary file was renamed
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only the output in %s, got %v", dir, entries)
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "motd"), []byte("new"), 0644, -1, -1); err == nil {
+		t.Errorf("expected an error writing to a missing directory")
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import "encoding/json"
+
+// Ring keeps the most recent samples up to its size, the oldest sample is replaced once it is full
+type Ring[T any] struct {
+	buf  []T
+	next int
+	full bool
+}
+
+// NewRing returns an empty ring holding up to size samples
+func NewRing[T any](size int) *Ring[T] {
+	return &Ring[T]{buf: make([]T, max(size, 1))}
+}
+
+// Add appends v, replacing the oldest sample if the ring is full
+func (r *Ring[T]) Add(v T) {
+	r.buf[r.next] = v
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Len returns the number of samples
+func (r *Ring[T]) Len() int {
+	if r.full {
+		return len(r.buf)
+	}
+	return r.next
+}
+
+// Values returns the samples from oldest to newest
+func (r *Ring[T]) Values() []T {
+	if !r.full {
+		return append([]T(nil), r.buf[:r.next]...)
+	}
+	return append(append([]T(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+// Resize returns a ring of size with the most recent samples of r
+func (r *Ring[T]) Resize(size int) *Ring[T] {
+	ret := NewRing[T](size)
+	values := r.Values()
+	for _, v := range values[max(len(values)-len(ret.buf), 0):] {
+		ret.Add(v)
+	}
+	return ret
+}
+
+// MarshalJSON encodes the samples from oldest to newest
+func (r *Ring[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Values())
+}
+
+// UnmarshalJSON decodes samples from oldest to newest, the size of the ring is the number of samples
+func (r *Ring[T]) UnmarshalJSON(b []byte) error {
+	var values []T
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	*r = *NewRing[T](len(values))
+	for _, v := range values {
+		r.Add(v)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing[float64](3)
+	if v := r.Values(); len(v) != 0 {
+		t.Errorf("expected no values, got %v", v)
+	}
+	for i := 1; i <= 4; i++ {
+		r.Add(float64(i))
+	}
+	if v, expected := r.Values(), []float64{2, 3, 4}; !reflect.DeepEqual(v, expected) {
+		t.Errorf("got %v, expected %v", v, expected)
+	}
+	if v, expected := r.Resize(2).Values(), []float64{3, 4}; !reflect.DeepEqual(v, expected) {
+		t.Errorf("resized: got %v, expected %v", v, expected)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Ring[float64]
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded.Add(5)
+	if v, expected := decoded.Values(), []float64{3, 4, 5}; !reflect.DeepEqual(v, expected) {
+		t.Errorf("decoded: got %v, expected %v", v, expected)
+	}
+}
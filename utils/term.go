@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Color modes
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ValidColorMode returns true if mode is auto, always or never
+func ValidColorMode(mode string) bool {
+	return mode == ColorAuto || mode == ColorAlways || mode == ColorNever
+}
+
+// IsTerminal returns true if f is a terminal
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
+// UseColors returns true if output written to f should be colored according to mode, f is nil for regular files.
+//
+// In auto mode colors are used if f is a terminal, unless NO_COLOR is set or TERM is dumb.
+func UseColors(mode string, f *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return f != nil && IsTerminal(f)
+}
+
+// TerminalWidth returns the number of columns of the terminal f, 0 if f is not a terminal
+func TerminalWidth(f *os.File) int {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestUseColors(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "motd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	// The master side of a pseudo terminal is a terminal
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err == nil {
+		defer tty.Close()
+	}
+	tests := []struct {
+		name     string
+		mode     string
+		f        *os.File
+		noColor  string
+		term     string
+		expected bool
+	}{
+		{"always", ColorAlways, nil, "1", "dumb", true},
+		{"never", ColorNever, tty, "", "xterm", false},
+		{"auto nil file", ColorAuto, nil, "", "xterm", false},
+		{"auto regular file", ColorAuto, file, "", "xterm", false},
+		{"auto terminal", ColorAuto, tty, "", "xterm", true},
+		{"auto NO_COLOR", ColorAuto, tty, "1", "xterm", false},
+		{"auto TERM=dumb", ColorAuto, tty, "", "dumb", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mode == ColorAuto && tt.expected && tty == nil {
+				t.Skip("no pseudo terminal available")
+			}
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("TERM", tt.term)
+			if actual := UseColors(tt.mode, tt.f); actual != tt.expected {
+				t.Errorf("got %v, expected %v", actual, tt.expected)
+			}
+		})
+	}
+}
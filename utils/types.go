@@ -1,35 +1,5 @@
 package utils
 
-import (
-	"bytes"
-	"fmt"
-	"strings"
-	"text/tabwriter"
-)
-
-// Pad holds the pad char and its number of spaces a map as well as the string itself
-type Pad struct {
-	Delims  map[string]int
-	Content string
-}
-
-// Do performs the padding
-func (p *Pad) Do() string {
-	var buf bytes.Buffer
-	var w *tabwriter.Writer
-	var withTabs = p.Content
-	// Replace padchar with tabs
-	for k, v := range p.Delims {
-		w = tabwriter.NewWriter(&buf, 0, 0, v, ' ', 0)
-		withTabs = strings.ReplaceAll(withTabs, k, "\t")
-		fmt.Fprint(w, withTabs)
-		w.Flush()
-		withTabs = buf.String()
-		buf.Reset()
-	}
-	return strings.TrimSuffix(withTabs, "\n")
-}
-
 // StringSet a set for strings, useful for keeping track of elements
 type StringSet map[string]struct{}
 
@@ -12,11 +12,6 @@ const (
 	kebibyte float64 = 1024
 )
 
-// Wrap `s` around start and end, returns <start>s<end>
-func Wrap(s string, start string, end string) string {
-	return fmt.Sprintf("%s%s%s", start, s, end)
-}
-
 // FormatBytes format bytes to TiB, GiB, MiB, KiB depending on the size
 func FormatBytes(sizeBytes float64) string {
 	if sizeBytes > tebibyte {
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// reANSI matches ANSI escape sequences such as colors
+var reANSI = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// StripANSI removes ANSI escape sequences from s
+func StripANSI(s string) string {
+	return reANSI.ReplaceAllString(s, "")
+}
+
+// VisibleWidth returns the number of terminal cells s occupies, escape sequences have no width and
+// wide characters such as CJK count as 2
+func VisibleWidth(s string) int {
+	return runewidth.StringWidth(StripANSI(s))
+}
+
+// PadRight appends spaces to s until its visible width is at least width
+func PadRight(s string, width int) string {
+	if w := VisibleWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// PadLeft prepends spaces to s until its visible width is at least width
+func PadLeft(s string, width int) string {
+	if w := VisibleWidth(s); w < width {
+		return strings.Repeat(" ", width-w) + s
+	}
+	return s
+}
+
+// PadCenter surrounds s with spaces until its visible width is at least width, the extra space goes to the right
+func PadCenter(s string, width int) string {
+	if w := VisibleWidth(s); w < width {
+		left := (width - w) / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-w-left)
+	}
+	return s
+}